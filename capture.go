@@ -0,0 +1,565 @@
+package mckio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+/*
+Capture redirects write operations targeted to a file, like
+FileCaptureStart, while delivering the captured output incrementally, so
+long-running tests can assert on output as it happens.
+
+- Writes streams each chunk of output as it's read from the redirected
+file.  A chunk usually corresponds to a single write, although writes
+issued faster than they're captured may be coalesced.
+
+- End terminates capturing, restores the redirected variable, and returns
+the aggregate of everything captured.
+
+- Stats reports the volume and timing of the captured output.  Its clock
+can be configured using BehaviorClocker.  When undefined - uses the system
+clock.
+
+- Not concurrency safe with respect to the redirected variable, like
+FileCaptureStart.  Its methods, however, may be called from any goroutine.
+
+- Do not attempt to read from the redirected variable while it's captured.
+*/
+type Capture struct {
+	rdr     *os.File
+	restore func()
+	cleanup func()
+	done    chan struct{}
+	mu      sync.Mutex
+	buf     *captureBuffer
+	chunks  []string
+	retain  bool
+	changed chan struct{}
+	err     error
+	endErr  error
+	stream  sync.Once
+	writes  chan string
+	lineOne sync.Once
+	lines   chan string
+	rawOne  sync.Once
+	raw     chan []byte
+	behave  interface{}
+	endOnce sync.Once
+	observe func(chunk []byte)
+	onWrt   func(p []byte)
+	// redirect and revert the variable without ending the capture.
+	pause  func()
+	resume func()
+	paused bool
+	ended  bool
+	clock  Clock
+	stats  CaptureStats
+	// once expired, chunks are discarded and Writes closes.
+	expire  Timer
+	expired bool
+	// hooks permitting a CaptureManager to coordinate nested captures.
+	beforeEnd func()
+	afterEnd  func()
+}
+
+/*
+BehaviorCaptureLimiter bounds the memory consumed by a capture, retaining
+only the first 'head' bytes and the last 'tail' bytes of the captured
+output.  A zero value for both retains everything.
+*/
+type BehaviorCaptureLimiter interface {
+	BehaviorCaptureLimit() (head int, tail int)
+}
+
+/*
+CaptureLimit implements BehaviorCaptureLimiter.  Embed it in a behavior
+struct to combine it with other behaviors.
+*/
+type CaptureLimit struct {
+	Head int
+	Tail int
+}
+
+/*
+BehaviorCaptureLimit returns Head and Tail.
+*/
+func (cl CaptureLimit) BehaviorCaptureLimit() (head int, tail int) {
+	return cl.Head, cl.Tail
+}
+
+/*
+CaptureStats reports the activity of a Capture.
+
+- Bytes - number of bytes captured, before any are stripped or discarded.
+
+- Writes - number of chunks captured.  A chunk usually corresponds to a
+single write, although writes may be coalesced.
+
+- First, Last - time the first and most recent chunks were captured.  Zero
+when nothing was captured.
+*/
+type CaptureStats struct {
+	Bytes  int64
+	Writes int64
+	First  time.Time
+	Last   time.Time
+}
+
+/*
+Duration returns the time elapsed between the first and most recent
+chunks captured.
+*/
+func (cs CaptureStats) Duration() time.Duration {
+	return cs.Last.Sub(cs.First)
+}
+
+/*
+FileCaptureStream starts capturing writes targeted to the variable
+referenced by 'osf'.  Its behavior can be configured using the behaviors
+accepted by FileCaptureStartBehavior.
+*/
+func FileCaptureStream(osf **os.File, behavior interface{}) (*Capture, error) {
+	c, err := fileCapture(osf, behavior, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.retain = true
+	return c, nil
+}
+
+/*
+FileCaptureReader redirects writes targeted to the variable referenced by
+'osf' into a pipe and returns the pipe's read end, so consumers can drive
+bufio.Scanner or io.Copy over the captured output directly.
+
+- The reader returns io.EOF once captureEnd is called and the output
+written before it has been read.
+
+- The pipe's capacity is limited, so read concurrently with the code under
+test, otherwise its writes will block.
+
+- captureEnd restores the variable to its original value.  Calling it more
+than once is harmless.  Close the reader once finished with it.
+*/
+func FileCaptureReader(osf **os.File) (rdr io.ReadCloser, captureEnd func() error, err error) {
+	prdr, wrt, err := os.Pipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	orig := *osf
+	*osf = wrt
+	var once sync.Once
+	captureEnd = func() (err error) {
+		once.Do(func() {
+			*osf = orig
+			err = wrt.Close()
+		})
+		return err
+	}
+	return prdr, captureEnd, nil
+}
+
+/*
+Writes returns a channel delivering each chunk of captured output.  For a
+capture started by FileCaptureStream, delivery begins with the first chunk
+captured, even when Writes is called after capturing has begun.  Otherwise,
+it begins with the chunks captured after Writes is first called.  Chunks
+are retained until delivered, regardless of the limits imposed by
+BehaviorCaptureLimiter.  The channel is closed once capturing ends and
+every chunk was delivered, so consume it until closed.
+*/
+func (c *Capture) Writes() <-chan string {
+	c.stream.Do(func() {
+		c.mu.Lock()
+		c.retain = true
+		c.mu.Unlock()
+		c.writes = make(chan string)
+		go c.emit()
+	})
+	return c.writes
+}
+
+/*
+Lines returns a channel delivering each complete line of captured output,
+excluding its delimiter, regardless of how the output was split across
+writes.  The final line, when unterminated, is delivered once capturing
+ends.  Lines are terminated by the delimiter specified by BehaviorDelimer
+or, when undefined, a newline.  Lines consumes the chunks delivered by
+Writes, so use one or the other.  Like Writes, consume the channel until
+it's closed.
+*/
+func (c *Capture) Lines() <-chan string {
+	c.lineOne.Do(func() {
+		c.lines = make(chan string)
+		wl := NewWlines(c.lines, c.behave)
+		writes := c.Writes()
+		go func() {
+			for chunk := range writes {
+				wl.WriteString(chunk)
+			}
+			wl.Close()
+		}()
+	})
+	return c.lines
+}
+
+/*
+WriteBytes returns a channel delivering each chunk of captured output as a
+byte slice, so binary output, like a tar stream, can be consumed without
+conversion.  Each chunk contains the bytes of one read from the capture's
+pipe which, for unbuffered writes issued by a single goroutine, usually
+mirrors a write, however, the pipe doesn't guarantee that writes aren't
+merged or split.  WriteBytes consumes the chunks delivered by Writes, so
+use one or the other.  Like Writes, consume the channel until it's closed.
+*/
+func (c *Capture) WriteBytes() <-chan []byte {
+	c.rawOne.Do(func() {
+		c.raw = make(chan []byte)
+		writes := c.Writes()
+		go func() {
+			defer close(c.raw)
+			for chunk := range writes {
+				c.raw <- []byte(chunk)
+			}
+		}()
+	})
+	return c.raw
+}
+
+/*
+End terminates capturing, restores the variable to its original value, and
+returns the aggregate of the captured output.  Calling it more than once
+returns the same result.  End waits for the output written before it to be
+captured, which never occurs when another process or descriptor retains the
+redirected file open.  Use EndContext to limit the wait.
+*/
+func (c *Capture) End() (string, error) {
+	return c.EndContext(context.Background())
+}
+
+/*
+EndContext terminates capturing like End, although it stops waiting for
+the remaining output once 'ctx' is done.  It then returns the output
+captured so far along with an error wrapping the context's error.
+*/
+func (c *Capture) EndContext(ctx context.Context) (string, error) {
+	c.endOnce.Do(func() {
+		if c.expire != nil {
+			c.expire.Stop()
+		}
+		if c.beforeEnd != nil {
+			c.beforeEnd()
+		}
+		c.mu.Lock()
+		c.ended = true
+		c.mu.Unlock()
+		c.restore()
+		select {
+		case <-c.done:
+		case <-ctx.Done():
+			c.mu.Lock()
+			c.endErr = fmt.Errorf("mckio: capture abandoned before its output ended: %w", ctx.Err())
+			c.mu.Unlock()
+			// closing the read end unblocks draining.
+			c.rdr.Close()
+			<-c.done
+		}
+		c.rdr.Close()
+		if c.cleanup != nil {
+			c.cleanup()
+		}
+		if c.afterEnd != nil {
+			c.afterEnd()
+		}
+	})
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.endErr != nil {
+		return c.buf.String(), c.endErr
+	}
+	return c.buf.String(), c.err
+}
+
+/*
+Stats reports the number of bytes and chunks captured so far, and when the
+first and most recent chunks were captured.
+*/
+func (c *Capture) Stats() CaptureStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+/*
+Pause temporarily reverts the variable to its original value, so output
+written while paused isn't captured, for example, to let phases of the
+program not under assertion print normally.  Pausing a paused capture has
+no effect.  Pause fails once the capture has ended.
+*/
+func (c *Capture) Pause() error {
+	return c.redirect(true)
+}
+
+/*
+Resume redirects the variable to the capture again, after Pause.  Resuming
+an active capture has no effect.  Resume fails once the capture has ended.
+*/
+func (c *Capture) Resume() error {
+	return c.redirect(false)
+}
+
+/*
+Discarded returns the number of captured bytes omitted from the aggregate
+due to the limits specified by BehaviorCaptureLimiter.
+*/
+func (c *Capture) Discarded() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.buf.discarded()
+}
+
+/*
+Raw returns the captured output including its escape sequences, when
+BehaviorCaptureAnsiStripper strips them and BehaviorKeepRawer enables
+keeping the unstripped output.  Otherwise, it's empty.
+*/
+func (c *Capture) Raw() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.buf.rawString()
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+func fileCapture(osf **os.File, behavior interface{}, observe func(chunk []byte)) (*Capture, error) {
+	rdr, wrt, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	orig := *osf
+	*osf = wrt
+	restore := func() {
+		*osf = orig
+		// closing the write end signals end of file to drain.
+		wrt.Close()
+	}
+	c := newCapture(rdr, restore, nil, behavior, orig, observe)
+	c.pause = func() { *osf = orig }
+	c.resume = func() { *osf = wrt }
+	return c, nil
+}
+
+// starts draining the pipe's read end.  restore must revert the redirect
+// and close every reference to the pipe's write end.  cleanup, when not nil,
+// executes once draining completes.
+func newCapture(rdr *os.File, restore func(), cleanup func(), behavior interface{}, orig *os.File, observe func(chunk []byte)) *Capture {
+	c := &Capture{
+		rdr:     rdr,
+		restore: restore,
+		cleanup: cleanup,
+		done:    make(chan struct{}),
+		buf:     newCaptureBuffer(behavior, orig),
+		changed: make(chan struct{}),
+		observe: observe,
+		behave:  behavior,
+		clock:   clockOf(behavior),
+	}
+	// invoke the caller's function outside the lock protecting the buffer,
+	// so it may call the capture's methods.
+	c.onWrt, c.buf.onWrt = c.buf.onWrt, nil
+	if d := captureMaxDuration(behavior); d > 0 {
+		c.expire = c.clock.NewTimer(d)
+		go c.expiry(d)
+	}
+	go c.drain()
+	return c
+}
+
+// stops recording once the capture expires, leaving the variable
+// redirected, as the program may still write it, until End restores it.
+func (c *Capture) expiry(d time.Duration) {
+	select {
+	case <-c.expire.C():
+	case <-c.done:
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ended {
+		return
+	}
+	c.expired = true
+	c.endErr = captureExpiredErr(d)
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// reads the pipe until end of file recording each chunk read.
+func (c *Capture) drain() {
+	defer close(c.done)
+	p := make([]byte, 32*1024)
+	for {
+		n, err := c.rdr.Read(p)
+		if n > 0 {
+			text := c.record(p[:n])
+			if c.onWrt != nil && len(text) > 0 {
+				c.onWrt(text)
+			}
+		}
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			c.mu.Lock()
+			c.err = err
+			c.mu.Unlock()
+			return
+		}
+	}
+}
+
+func (c *Capture) redirect(pause bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case c.ended:
+		return errors.New("mckio: capture ended")
+	case c.pause == nil:
+		return errors.New("mckio: capture can't be paused")
+	case c.paused == pause:
+		return nil
+	case pause:
+		c.pause()
+	default:
+		c.resume()
+	}
+	c.paused = pause
+	return nil
+}
+
+// records a chunk returning its text that remains after filtering.
+func (c *Capture) record(chunk []byte) []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.expired {
+		// drain the pipe, so the program writing it doesn't block.
+		return nil
+	}
+	c.stats.Last = c.clock.Now()
+	if c.stats.Writes == 0 {
+		c.stats.First = c.stats.Last
+	}
+	c.stats.Writes++
+	c.stats.Bytes += int64(len(chunk))
+	text := c.buf.filter(chunk)
+	if len(text) == 0 {
+		return text
+	}
+	if _, err := c.buf.store(text); err != nil && c.err == nil {
+		c.err = err
+	}
+	if c.retain {
+		c.chunks = append(c.chunks, string(text))
+	}
+	if c.observe != nil {
+		c.observe(text)
+	}
+	close(c.changed)
+	c.changed = make(chan struct{})
+	return text
+}
+
+// delivers recorded chunks to the Writes channel until capturing ends.
+func (c *Capture) emit() {
+	defer close(c.writes)
+	for {
+		c.mu.Lock()
+		pending := c.chunks
+		// release delivered chunks
+		c.chunks = nil
+		changed, expired := c.changed, c.expired
+		c.mu.Unlock()
+		for _, chunk := range pending {
+			c.writes <- chunk
+		}
+		if len(pending) > 0 {
+			continue
+		}
+		if expired {
+			return
+		}
+		select {
+		case <-changed:
+		case <-c.done:
+			c.mu.Lock()
+			finished := len(c.chunks) == 0
+			c.mu.Unlock()
+			if finished {
+				return
+			}
+		}
+	}
+}
+
+// retains the first head bytes written and the last tail bytes, counting
+// the bytes discarded in between.
+type captureLimit struct {
+	head      []byte
+	headMax   int
+	ring      []byte
+	start     int
+	full      bool
+	discarded int64
+}
+
+func newCaptureLimit(head int, tail int) *captureLimit {
+	return &captureLimit{headMax: head, ring: make([]byte, 0, tail)}
+}
+func (cl *captureLimit) Write(p []byte) (int, error) {
+	n := len(p)
+	if room := cl.headMax - len(cl.head); room > 0 {
+		if room > len(p) {
+			room = len(p)
+		}
+		cl.head = append(cl.head, p[:room]...)
+		p = p[room:]
+	}
+	tail := cap(cl.ring)
+	if tail == 0 {
+		cl.discarded += int64(len(p))
+		return n, nil
+	}
+	if len(p) > tail {
+		// only the final tail bytes of p can survive.
+		cl.discarded += int64(len(p) - tail)
+		p = p[len(p)-tail:]
+	}
+	for _, b := range p {
+		if !cl.full {
+			cl.ring = append(cl.ring, b)
+			cl.full = len(cl.ring) == tail
+			continue
+		}
+		cl.ring[cl.start] = b
+		cl.start = (cl.start + 1) % tail
+		cl.discarded++
+	}
+	return n, nil
+}
+func (cl *captureLimit) String() string {
+	out := make([]byte, 0, len(cl.head)+len(cl.ring))
+	out = append(out, cl.head...)
+	out = append(out, cl.ring[cl.start:]...)
+	out = append(out, cl.ring[:cl.start]...)
+	return string(out)
+}
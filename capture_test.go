@@ -0,0 +1,416 @@
+package mckio
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CaptureStream(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	capt, err := FileCaptureStream(&captFile, nil)
+	assrt.Nil(err)
+	writes := capt.Writes()
+	for _, msg := range []string{"line 1\n", "line 2\n"} {
+		fmt.Fprint(captFile, msg)
+		// each write is observable before capturing ends
+		assrt.Equal(msg, <-writes)
+	}
+	output, err := capt.End()
+	assrt.Nil(err)
+	assrt.Equal("line 1\nline 2\n", output)
+	assrt.Nil(captFile)
+	_, ok := <-writes
+	assrt.False(ok)
+	// End is idempotent
+	output, err = capt.End()
+	assrt.Equal("line 1\nline 2\n", output)
+	assrt.Nil(err)
+}
+func Test_CaptureStreamLate(t *testing.T) {
+	assrt := assert.New(t)
+	capt, err := FileCaptureStream(&os.Stdout, nil)
+	assrt.Nil(err)
+	fmt.Print("line 1\n")
+	output, _ := capt.End()
+	assrt.Equal("line 1\n", output)
+	var rslt string
+	for chunk := range capt.Writes() {
+		rslt += chunk
+	}
+	assrt.Equal(output, rslt)
+}
+func Test_CaptureStreamEmpty(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	capt, err := FileCaptureStream(&captFile, nil)
+	assrt.Nil(err)
+	output, err := capt.End()
+	assrt.Empty(output)
+	assrt.Nil(err)
+	_, ok := <-capt.Writes()
+	assrt.False(ok)
+}
+func Test_CaptureReader(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	rdr, captureEnd, err := FileCaptureReader(&captFile)
+	assrt.Nil(err)
+	defer rdr.Close()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer captureEnd()
+		for i := 1; i <= 3; i++ {
+			fmt.Fprintf(captFile, "line %d\n", i)
+		}
+	}()
+	var lines []string
+	scan := bufio.NewScanner(rdr)
+	for scan.Scan() {
+		lines = append(lines, scan.Text())
+	}
+	assrt.Nil(scan.Err())
+	assrt.Equal([]string{"line 1", "line 2", "line 3"}, lines)
+	<-done
+	assrt.Nil(captFile)
+	assrt.Nil(captureEnd())
+}
+func Test_CaptureTee(t *testing.T) {
+	assrt := assert.New(t)
+	rdr, orig, err := os.Pipe()
+	assrt.Nil(err)
+	defer rdr.Close()
+	captFile := orig
+	output, captureEnd, err := FileCaptureStartBehavior(&captFile, captureTee(true))
+	assrt.Nil(err)
+	fmt.Fprint(captFile, "line 1\n")
+	captureEnd()
+	assrt.Equal("line 1\n", <-output)
+	assrt.Equal(orig, captFile)
+	orig.Close()
+	forwarded, err := io.ReadAll(rdr)
+	assrt.Nil(err)
+	assrt.Equal("line 1\n", string(forwarded))
+}
+func Test_CaptureStreamTee(t *testing.T) {
+	assrt := assert.New(t)
+	rdr, orig, err := os.Pipe()
+	assrt.Nil(err)
+	defer rdr.Close()
+	forwarded := make(chan string)
+	go func() {
+		p, _ := io.ReadAll(rdr)
+		forwarded <- string(p)
+	}()
+	captFile := orig
+	capt, err := FileCaptureStream(&captFile, captureTee(true))
+	assrt.Nil(err)
+	fmt.Fprint(captFile, "line 1\n")
+	output, _ := capt.End()
+	assrt.Equal("line 1\n", output)
+	orig.Close()
+	assrt.Equal("line 1\n", <-forwarded)
+}
+
+type captureTee bool
+
+func (ct captureTee) BehaviorCaptureTee() bool {
+	return bool(ct)
+}
+
+func Test_CaptureWriteBytes(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	capt, err := FileCaptureStream(&captFile, nil)
+	assrt.Nil(err)
+	chunks := capt.WriteBytes()
+	bin := []byte{0x00, 0xff, 0x1f, 0x8b}
+	captFile.Write(bin)
+	assrt.Equal(bin, <-chunks)
+	captFile.Write(bin[:2])
+	assrt.Equal(bin[:2], <-chunks)
+	capt.End()
+	_, ok := <-chunks
+	assrt.False(ok)
+}
+
+func Test_CaptureOnWrite(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	var seen []string
+	var capt *Capture
+	capt, err := FileCaptureStream(&captFile, WithOnWrite(func(p []byte) {
+		// the capture's methods remain callable
+		assrt.Equal(int64(0), capt.Discarded())
+		seen = append(seen, string(p))
+	}))
+	assrt.Nil(err)
+	fmt.Fprint(captFile, "prompt> ")
+	output, _ := capt.End()
+	assrt.Equal("prompt> ", output)
+	assrt.Equal([]string{"prompt> "}, seen)
+}
+func Test_FileCaptureOnWrite(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	prompt := make(chan string, 1)
+	_, captureEnd, err := FileCaptureStartErr(&captFile, WithOnWrite(func(p []byte) {
+		prompt <- string(p)
+	}))
+	assrt.Nil(err)
+	fmt.Fprint(captFile, "prompt> ")
+	assrt.Equal("prompt> ", <-prompt)
+	assrt.Nil(captureEnd())
+}
+
+func Test_CapturePause(t *testing.T) {
+	assrt := assert.New(t)
+	var orig *os.File
+	captFile := orig
+	capt, err := FileCaptureStream(&captFile, nil)
+	assrt.Nil(err)
+	fmt.Fprint(captFile, "line 1\n")
+	assrt.Nil(capt.Pause())
+	assrt.Nil(capt.Pause())
+	assrt.Equal(orig, captFile)
+	assrt.Nil(capt.Resume())
+	assrt.NotEqual(orig, captFile)
+	fmt.Fprint(captFile, "line 2\n")
+	assrt.Nil(capt.Pause())
+	output, _ := capt.End()
+	assrt.Equal("line 1\nline 2\n", output)
+	assrt.Equal(orig, captFile)
+	assrt.NotNil(capt.Resume())
+	assrt.Equal(orig, captFile)
+}
+
+func Test_CaptureStats(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	clock := &stepClock{at: time.Unix(0, 0), step: time.Second}
+	capt, err := FileCaptureStream(&captFile, clock)
+	assrt.Nil(err)
+	assrt.Equal(CaptureStats{}, capt.Stats())
+	writes := capt.Writes()
+	fmt.Fprint(captFile, "line 1\n")
+	<-writes
+	fmt.Fprint(captFile, "line 22\n")
+	<-writes
+	capt.End()
+	stats := capt.Stats()
+	assrt.Equal(int64(15), stats.Bytes)
+	assrt.Equal(int64(2), stats.Writes)
+	assrt.Equal(time.Second, stats.Duration())
+}
+
+type maxDuration time.Duration
+
+func (md maxDuration) BehaviorCaptureMaxDuration() time.Duration {
+	return time.Duration(md)
+}
+func Test_CaptureMaxDuration(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	capt, err := FileCaptureStream(&captFile, maxDuration(20*time.Millisecond))
+	assrt.Nil(err)
+	fmt.Fprint(captFile, "partial")
+	// closed once the capture expires
+	for range capt.Writes() {
+	}
+	// remains redirected until ended, as the program may still write it.
+	assrt.NotNil(captFile)
+	fmt.Fprint(captFile, " discarded")
+	output, err := capt.End()
+	assrt.True(errors.Is(err, os.ErrDeadlineExceeded))
+	assrt.Equal("partial", output)
+	assrt.Nil(captFile)
+}
+
+type maxDurationClock struct {
+	maxDuration
+	*FakeClock
+}
+
+func Test_CaptureMaxDurationClock(t *testing.T) {
+	assrt := assert.New(t)
+	fc := NewFakeClock(time.Time{})
+	var captFile *os.File
+	capt, err := FileCaptureStream(&captFile, maxDurationClock{maxDuration(time.Hour), fc})
+	assrt.Nil(err)
+	writes := capt.Writes()
+	fmt.Fprint(captFile, "partial")
+	assrt.Equal("partial", <-writes)
+	fakeClockWait(fc, 1)
+	fc.Advance(time.Hour)
+	_, open := <-writes
+	assrt.False(open)
+	fmt.Fprint(captFile, " discarded")
+	output, err := capt.End()
+	assrt.True(errors.Is(err, os.ErrDeadlineExceeded))
+	assrt.Equal("partial", output)
+}
+func Test_CaptureMaxDurationUnexpired(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	capt, err := FileCaptureStream(&captFile, maxDuration(time.Hour))
+	assrt.Nil(err)
+	fmt.Fprint(captFile, "line 1\n")
+	output, err := capt.End()
+	assrt.Nil(err)
+	assrt.Equal("line 1\n", output)
+}
+func Test_FileCaptureMaxDuration(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	output, captureEnd, err := FileCaptureStartErr(&captFile, maxDuration(20*time.Millisecond))
+	assrt.Nil(err)
+	fmt.Fprint(captFile, "partial")
+	assrt.Equal("partial", <-output)
+	assrt.NotNil(captFile)
+	assrt.True(errors.Is(captureEnd(), os.ErrDeadlineExceeded))
+	assrt.Nil(captFile)
+}
+func Test_FileCaptureMaxDurationClock(t *testing.T) {
+	assrt := assert.New(t)
+	fc := NewFakeClock(time.Time{})
+	var captFile *os.File
+	captured := make(chan struct{})
+	output, captureEnd, err := FileCaptureStartErr(&captFile, struct {
+		maxDurationClock
+		WithOnWrite
+	}{maxDurationClock{maxDuration(time.Hour), fc}, func([]byte) { close(captured) }})
+	assrt.Nil(err)
+	fmt.Fprint(captFile, "partial")
+	<-captured
+	fc.Advance(time.Hour)
+	assrt.Equal("partial", <-output)
+	fmt.Fprint(captFile, " discarded")
+	assrt.True(errors.Is(captureEnd(), os.ErrDeadlineExceeded))
+	assrt.Nil(captFile)
+}
+
+type captureAnsi struct {
+	keepRaw
+}
+
+func (captureAnsi) BehaviorCaptureAnsiStrip() bool {
+	return true
+}
+func Test_CaptureAnsiStrip(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	capt, err := FileCaptureStream(&captFile, captureAnsi{keepRaw: true})
+	assrt.Nil(err)
+	writes := capt.Writes()
+	fmt.Fprint(captFile, "\x1b[32mok\x1b")
+	assrt.Equal("ok", <-writes)
+	// sequence split across writes
+	fmt.Fprint(captFile, "[0m\n")
+	assrt.Equal("\n", <-writes)
+	output, _ := capt.End()
+	assrt.Equal("ok\n", output)
+	assrt.Equal("\x1b[32mok\x1b[0m\n", capt.Raw())
+}
+func Test_FileCaptureAnsiStrip(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	output, captureEnd, err := FileCaptureStartErr(&captFile, captureAnsi{})
+	assrt.Nil(err)
+	fmt.Fprint(captFile, "\x1b[1mbold\x1b[0m")
+	assrt.Nil(captureEnd())
+	assrt.Equal("bold", <-output)
+}
+
+type captureDest struct {
+	io.Writer
+}
+
+func (cd captureDest) BehaviorCaptureDest() io.Writer {
+	return cd.Writer
+}
+func Test_CaptureDest(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	var dest bytes.Buffer
+	output, captureEnd, err := FileCaptureStartErr(&captFile, captureDest{&dest})
+	assrt.Nil(err)
+	fmt.Fprint(captFile, "line 1\x00\n")
+	assrt.Nil(captureEnd())
+	_, ok := <-output
+	assrt.False(ok)
+	assrt.Equal("line 1\x00\n", dest.String())
+}
+func Test_CaptureDestErr(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	errWrite := errors.New("sink failed")
+	capt, err := FileCaptureStream(&captFile, captureDest{NewWerr(nil, FailAfterCalls{Err: errWrite})})
+	assrt.Nil(err)
+	fmt.Fprint(captFile, "line 1\n")
+	output, err := capt.End()
+	assrt.True(errors.Is(err, errWrite))
+	assrt.Equal("", output)
+}
+func Test_CaptureLimit(t *testing.T) {
+	assrt := assert.New(t)
+	tests := []struct {
+		limit     CaptureLimit
+		expected  string
+		discarded int64
+	}{
+		{CaptureLimit{Head: 4, Tail: 3}, "0123xyz", 29},
+		{CaptureLimit{Head: 4}, "0123", 32},
+		{CaptureLimit{Tail: 5}, "vwxyz", 31},
+		{CaptureLimit{Head: 40, Tail: 5}, "0123456789abcdefghijklmnopqrstuvwxyz", 0},
+		{CaptureLimit{}, "0123456789abcdefghijklmnopqrstuvwxyz", 0},
+	}
+	for _, tst := range tests {
+		var captFile *os.File
+		capt, err := FileCaptureStream(&captFile, tst.limit)
+		assrt.Nil(err)
+		for _, chunk := range []string{"0123456789", "a", "bcdefghijklmnopq", "rstuvwxyz"} {
+			fmt.Fprint(captFile, chunk)
+		}
+		output, _ := capt.End()
+		assrt.Equal(tst.expected, output)
+		assrt.Equal(tst.discarded, capt.Discarded())
+	}
+}
+func Test_CaptureLines(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	capt, err := FileCaptureStream(&captFile, nil)
+	assrt.Nil(err)
+	lines := capt.Lines()
+	fmt.Fprint(captFile, "line 1\nli")
+	assrt.Equal("line 1", <-lines)
+	fmt.Fprint(captFile, "ne 2\nline 3")
+	assrt.Equal("line 2", <-lines)
+	output, _ := capt.End()
+	assrt.Equal("line 3", <-lines)
+	_, ok := <-lines
+	assrt.False(ok)
+	assrt.Equal("line 1\nline 2\nline 3", output)
+}
+func Test_CaptureLinesDelim(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	capt, err := FileCaptureStream(&captFile, wsDelim("\x00"))
+	assrt.Nil(err)
+	fmt.Fprint(captFile, "rec 1\x00rec 2\x00")
+	capt.End()
+	var recs []string
+	for rec := range capt.Lines() {
+		recs = append(recs, rec)
+	}
+	assrt.Equal([]string{"rec 1", "rec 2"}, recs)
+}
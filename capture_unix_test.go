@@ -0,0 +1,37 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package mckio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CaptureEndContext(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	capt, err := FileCaptureStream(&captFile, nil)
+	assrt.Nil(err)
+	fmt.Fprint(captFile, "line 1\n")
+	// a retained descriptor, like one inherited by a child process,
+	// keeps the pipe open after capturing ends.
+	fd, err := syscall.Dup(int(captFile.Fd()))
+	assrt.Nil(err)
+	defer syscall.Close(fd)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	output, err := capt.EndContext(ctx)
+	assrt.True(errors.Is(err, context.DeadlineExceeded))
+	assrt.Equal("line 1\n", output)
+	assrt.Nil(captFile)
+	// subsequent calls don't wait
+	_, err = capt.End()
+	assrt.True(errors.Is(err, context.DeadlineExceeded))
+}
@@ -0,0 +1,57 @@
+package mckio
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+/*
+AssertContains ends the capture, then fails the test 'tb' unless the
+captured output contains substr.  It reports whether the assertion passed.
+Ending a capture more than once is harmless, so several assertions may be
+applied to the same capture.  Errors ending the capture aren't asserted.
+*/
+func (c *Capture) AssertContains(tb testing.TB, substr string) bool {
+	tb.Helper()
+	output, _ := c.End()
+	if strings.Contains(output, substr) {
+		return true
+	}
+	tb.Errorf("mckio: captured output \"%s\" doesn't contain %q", excerpt(output), substr)
+	return false
+}
+
+/*
+AssertLineMatches ends the capture, then fails the test 'tb' unless a line
+of the captured output, excluding its newline, matches the regular
+expression expr.  It panics if expr fails to compile, as a malformed
+expression is a defect of the test itself.  It reports whether the
+assertion passed.
+*/
+func (c *Capture) AssertLineMatches(tb testing.TB, expr string) bool {
+	tb.Helper()
+	re := regexp.MustCompile(expr)
+	output, _ := c.End()
+	for _, line := range strings.Split(strings.TrimSuffix(output, "\n"), "\n") {
+		if re.MatchString(line) {
+			return true
+		}
+	}
+	tb.Errorf("mckio: no line of captured output \"%s\" matches %q", excerpt(output), expr)
+	return false
+}
+
+/*
+AssertEmpty ends the capture, then fails the test 'tb' unless nothing was
+captured.  It reports whether the assertion passed.
+*/
+func (c *Capture) AssertEmpty(tb testing.TB) bool {
+	tb.Helper()
+	output, _ := c.End()
+	if output == "" {
+		return true
+	}
+	tb.Errorf("mckio: captured output \"%s\" isn't empty", excerpt(output))
+	return false
+}
@@ -0,0 +1,34 @@
+package mckio
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CaptureAssertContains(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	capt, err := FileCaptureStream(&captFile, nil)
+	assrt.Nil(err)
+	fmt.Fprint(captFile, "usage: cmd [flags]\nversion 1.2\n")
+	assrt.True(capt.AssertContains(t, "usage:"))
+	assrt.True(capt.AssertLineMatches(t, `^version \d+\.\d+$`))
+	assrt.Nil(captFile)
+	tb := &tbRecord{TB: t}
+	assrt.False(capt.AssertContains(tb, "error"))
+	assrt.False(capt.AssertLineMatches(tb, `^cmd`))
+	assrt.False(capt.AssertEmpty(tb))
+	assrt.Len(tb.errs, 3)
+	assrt.Contains(tb.errs[0], `doesn't contain "error"`)
+}
+func Test_CaptureAssertEmpty(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	capt, err := FileCaptureStream(&captFile, nil)
+	assrt.Nil(err)
+	assrt.True(capt.AssertEmpty(t))
+	assrt.Panics(func() { capt.AssertLineMatches(t, `(`) })
+}
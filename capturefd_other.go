@@ -0,0 +1,16 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package mckio
+
+import (
+	"errors"
+	"os"
+)
+
+/*
+FileCaptureFd captures writes targeted to the descriptor of 'f' by
+redirecting the descriptor itself.  It's unsupported on this platform.
+*/
+func FileCaptureFd(f *os.File, behavior interface{}) (*Capture, error) {
+	return nil, errors.New("mckio: descriptor capture unsupported on this platform")
+}
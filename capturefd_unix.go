@@ -0,0 +1,58 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package mckio
+
+import (
+	"os"
+	"syscall"
+)
+
+/*
+FileCaptureFd captures writes targeted to the descriptor of 'f', like
+os.Stdout, by redirecting the descriptor itself using dup2.  Unlike
+FileCaptureStream, which replaces the *os.File held by a variable, it also
+captures writes issued through a cached descriptor, like the value of
+os.Stdout.Fd(), or by C libraries.
+
+- The descriptor is restored to its original target by End.
+
+- Its behavior can be configured using the behaviors accepted by
+FileCaptureStartBehavior.  BehaviorCaptureTeer forwards output to the
+descriptor's original target.
+
+- Not concurrency safe with respect to the descriptor, which is shared by
+the entire process.
+
+- Available on Unix-like platforms.
+*/
+func FileCaptureFd(f *os.File, behavior interface{}) (*Capture, error) {
+	fd := int(f.Fd())
+	saved, err := syscall.Dup(fd)
+	if err != nil {
+		return nil, os.NewSyscallError("dup", err)
+	}
+	rdr, wrt, err := os.Pipe()
+	if err != nil {
+		syscall.Close(saved)
+		return nil, err
+	}
+	if err := dup2(int(wrt.Fd()), fd); err != nil {
+		rdr.Close()
+		wrt.Close()
+		syscall.Close(saved)
+		return nil, os.NewSyscallError("dup2", err)
+	}
+	orig := os.NewFile(uintptr(saved), f.Name())
+	restore := func() {
+		dup2(saved, fd)
+		// closing the pipe's last write reference signals end of file.
+		wrt.Close()
+	}
+	// the original remains open until draining completes, as it may receive
+	// output forwarded by BehaviorCaptureTeer.
+	cleanup := func() { orig.Close() }
+	c := newCapture(rdr, restore, cleanup, behavior, orig, nil)
+	c.pause = func() { dup2(saved, fd) }
+	c.resume = func() { dup2(int(wrt.Fd()), fd) }
+	return c, nil
+}
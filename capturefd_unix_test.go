@@ -0,0 +1,69 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package mckio
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CaptureFdCached(t *testing.T) {
+	assrt := assert.New(t)
+	rdr, wrt, err := os.Pipe()
+	assrt.Nil(err)
+	defer rdr.Close()
+	defer wrt.Close()
+	// code under test caching the descriptor, bypassing the variable
+	fd := int(wrt.Fd())
+	capt, err := FileCaptureFd(wrt, nil)
+	assrt.Nil(err)
+	syscall.Write(fd, []byte("cached\n"))
+	fmt.Fprint(wrt, "variable\n")
+	output, err := capt.End()
+	assrt.Nil(err)
+	assrt.Equal("cached\nvariable\n", output)
+	// descriptor restored to its original target
+	fmt.Fprint(wrt, "restored\n")
+	p := make([]byte, 16)
+	sz, _ := rdr.Read(p)
+	assrt.Equal("restored\n", string(p[:sz]))
+}
+func Test_CaptureFdTee(t *testing.T) {
+	assrt := assert.New(t)
+	rdr, wrt, err := os.Pipe()
+	assrt.Nil(err)
+	defer rdr.Close()
+	defer wrt.Close()
+	capt, err := FileCaptureFd(wrt, captureTee(true))
+	assrt.Nil(err)
+	fmt.Fprint(wrt, "line 1\n")
+	output, _ := capt.End()
+	assrt.Equal("line 1\n", output)
+	p := make([]byte, 16)
+	sz, _ := rdr.Read(p)
+	assrt.Equal("line 1\n", string(p[:sz]))
+}
+func Test_CaptureFdPause(t *testing.T) {
+	assrt := assert.New(t)
+	rdr, wrt, err := os.Pipe()
+	assrt.Nil(err)
+	defer rdr.Close()
+	defer wrt.Close()
+	capt, err := FileCaptureFd(wrt, nil)
+	assrt.Nil(err)
+	fmt.Fprint(wrt, "captured 1\n")
+	assrt.Nil(capt.Pause())
+	fmt.Fprint(wrt, "printed\n")
+	assrt.Nil(capt.Resume())
+	fmt.Fprint(wrt, "captured 2\n")
+	output, err := capt.End()
+	assrt.Nil(err)
+	assrt.Equal("captured 1\ncaptured 2\n", output)
+	p := make([]byte, 16)
+	sz, _ := rdr.Read(p)
+	assrt.Equal("printed\n", string(p[:sz]))
+}
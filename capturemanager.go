@@ -0,0 +1,130 @@
+package mckio
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+/*
+CaptureManager coordinates captures of the same file, so tests capturing
+os.Stdout, for example, don't trample each other.
+
+- Captures of a file by the same test, or its subtests, nest.  Ending an
+inner capture restores the file to the outer capture's redirect.  Ending an
+outer capture first ends the captures nested within it.
+
+- Captures of a file by different tests, like those running in parallel,
+are serialized.  A capture waits until the file is released by the test
+currently capturing it.
+
+- Captures end automatically when the test that started them completes.
+
+- The zero value is ready to use.  CaptureManager is concurrency safe,
+although the code under test may still race reading a variable while it's
+being redirected.
+*/
+type CaptureManager struct {
+	mu    sync.Mutex
+	files map[**os.File]*managedFile
+}
+
+/*
+FileCaptureManaged starts a capture of the variable referenced by 'osf'
+coordinated by a CaptureManager shared by the whole package.
+*/
+func FileCaptureManaged(tb testing.TB, osf **os.File, behavior interface{}) (*Capture, error) {
+	return defaultCaptures.Start(tb, osf, behavior)
+}
+
+/*
+Start begins a capture of the variable referenced by 'osf' on behalf of
+the test 'tb', waiting while another test captures it.  Its behavior can be
+configured using the behaviors accepted by FileCaptureStartBehavior.
+*/
+func (cm *CaptureManager) Start(tb testing.TB, osf **os.File, behavior interface{}) (*Capture, error) {
+	name := tb.Name()
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if cm.files == nil {
+		cm.files = make(map[**os.File]*managedFile)
+	}
+	for {
+		mf, ok := cm.files[osf]
+		if !ok {
+			mf = &managedFile{owner: name, free: make(chan struct{})}
+			cm.files[osf] = mf
+		}
+		if mf.owns(name) {
+			capt, err := fileCapture(osf, behavior, nil)
+			if err != nil {
+				if len(mf.stack) == 0 {
+					cm.release(osf, mf)
+				}
+				return nil, err
+			}
+			capt.beforeEnd = func() { cm.endNested(mf, capt) }
+			capt.afterEnd = func() { cm.pop(osf, mf, capt) }
+			mf.stack = append(mf.stack, capt)
+			tb.Cleanup(func() { capt.End() })
+			return capt, nil
+		}
+		cm.mu.Unlock()
+		<-mf.free
+		cm.mu.Lock()
+	}
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+var defaultCaptures CaptureManager
+
+// captures of a single file, the outermost one at the stack's bottom.
+type managedFile struct {
+	owner string
+	stack []*Capture
+	free  chan struct{}
+}
+
+// subtests are named after their parent followed by a slash.
+func (mf *managedFile) owns(name string) bool {
+	return name == mf.owner || strings.HasPrefix(name, mf.owner+"/")
+}
+
+// ends, innermost first, the captures nested within capt.
+func (cm *CaptureManager) endNested(mf *managedFile, capt *Capture) {
+	cm.mu.Lock()
+	var nested []*Capture
+	for i, c := range mf.stack {
+		if c == capt {
+			nested = append(nested, mf.stack[i+1:]...)
+			break
+		}
+	}
+	cm.mu.Unlock()
+	for i := len(nested) - 1; i > -1; i-- {
+		nested[i].End()
+	}
+}
+func (cm *CaptureManager) pop(osf **os.File, mf *managedFile, capt *Capture) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	for i, c := range mf.stack {
+		if c == capt {
+			mf.stack = append(mf.stack[:i], mf.stack[i+1:]...)
+			break
+		}
+	}
+	if len(mf.stack) == 0 {
+		cm.release(osf, mf)
+	}
+}
+
+// frees the file for captures by other tests.
+func (cm *CaptureManager) release(osf **os.File, mf *managedFile) {
+	delete(cm.files, osf)
+	close(mf.free)
+}
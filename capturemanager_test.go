@@ -0,0 +1,85 @@
+package mckio
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CaptureManagerNested(t *testing.T) {
+	assrt := assert.New(t)
+	var cm CaptureManager
+	var captFile *os.File
+	outer, err := cm.Start(t, &captFile, nil)
+	assrt.Nil(err)
+	fmt.Fprint(captFile, "outer 1\n")
+	inner, err := cm.Start(t, &captFile, nil)
+	assrt.Nil(err)
+	fmt.Fprint(captFile, "inner\n")
+	output, _ := inner.End()
+	assrt.Equal("inner\n", output)
+	// restored to the outer capture's redirect
+	fmt.Fprint(captFile, "outer 2\n")
+	output, _ = outer.End()
+	assrt.Equal("outer 1\nouter 2\n", output)
+	assrt.Nil(captFile)
+}
+func Test_CaptureManagerOuterFirst(t *testing.T) {
+	assrt := assert.New(t)
+	var cm CaptureManager
+	var captFile *os.File
+	outer, _ := cm.Start(t, &captFile, nil)
+	var inner *Capture
+	t.Run("sub", func(t *testing.T) {
+		// subtests nest instead of waiting for their parent
+		inner, _ = cm.Start(t, &captFile, nil)
+		fmt.Fprint(captFile, "inner\n")
+		outer.End()
+	})
+	assrt.Nil(captFile)
+	output, _ := inner.End()
+	assrt.Equal("inner\n", output)
+}
+func Test_CaptureManagerSerialize(t *testing.T) {
+	assrt := assert.New(t)
+	var cm CaptureManager
+	var captFile *os.File
+	first, _ := cm.Start(tbName{TB: t, name: "TestFirst"}, &captFile, nil)
+	started := make(chan *Capture)
+	go func() {
+		second, _ := cm.Start(tbName{TB: t, name: "TestSecond"}, &captFile, nil)
+		started <- second
+	}()
+	select {
+	case <-started:
+		assrt.Fail("capture by another test should wait")
+	case <-time.After(10 * time.Millisecond):
+	}
+	first.End()
+	second := <-started
+	fmt.Fprint(captFile, "second\n")
+	output, _ := second.End()
+	assrt.Equal("second\n", output)
+	assrt.Nil(captFile)
+}
+func Test_CaptureManagerCleanup(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	t.Run("sub", func(t *testing.T) {
+		FileCaptureManaged(t, &captFile, nil)
+		assrt.NotNil(captFile)
+	})
+	assrt.Nil(captFile)
+}
+
+type tbName struct {
+	testing.TB
+	name string
+}
+
+func (tn tbName) Name() string {
+	return tn.name
+}
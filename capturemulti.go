@@ -0,0 +1,143 @@
+package mckio
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+/*
+MultiCapture captures several files at once, like os.Stdout and os.Stderr,
+merging their output into a single transcript that preserves the order in
+which it was captured.  Capturing each file separately loses the
+interleaving that's often what a test cares about.
+
+- Each chunk of the transcript is tagged with its source: the index of the
+file within the arguments supplied to FileCaptureMulti.
+
+- Order is established as output is read from each file's pipe, so writes
+to different files issued in quick succession by concurrent goroutines may
+be captured in either order.  Writes from a single goroutine are captured
+in the order issued.
+
+- Not concurrency safe with respect to the redirected variables, like
+FileCaptureStart.
+*/
+type MultiCapture struct {
+	mu     sync.Mutex
+	caps   []*Capture
+	chunks []CaptureChunk
+}
+
+/*
+CaptureChunk is a portion of output captured from the file identified by
+Source.
+*/
+type CaptureChunk struct {
+	Source int
+	Data   string
+}
+
+/*
+FileCaptureMulti starts capturing writes targeted to every variable
+referenced by 'files'.  A failure to capture any one of them ends the
+captures already started.
+*/
+func FileCaptureMulti(files ...**os.File) (*MultiCapture, error) {
+	mc := &MultiCapture{}
+	for i, osf := range files {
+		src := i
+		capt, err := fileCapture(osf, nil, func(chunk []byte) {
+			mc.mu.Lock()
+			defer mc.mu.Unlock()
+			mc.chunks = append(mc.chunks, CaptureChunk{Source: src, Data: string(chunk)})
+		})
+		if err != nil {
+			mc.End()
+			return nil, err
+		}
+		mc.caps = append(mc.caps, capt)
+	}
+	return mc, nil
+}
+
+/*
+End terminates capturing, restores every variable to its original value,
+and returns the merged transcript.  It reports the first error encountered
+while capturing any of the files.  Calling it more than once returns the
+same result.
+*/
+func (mc *MultiCapture) End() ([]CaptureChunk, error) {
+	var err error
+	for _, capt := range mc.caps {
+		if _, cerr := capt.End(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	return append([]CaptureChunk(nil), mc.chunks...), err
+}
+
+/*
+Transcript concatenates the data of chunks, ignoring their source.
+*/
+func Transcript(chunks []CaptureChunk) string {
+	var tscpt strings.Builder
+	for _, chunk := range chunks {
+		tscpt.WriteString(chunk.Data)
+	}
+	return tscpt.String()
+}
+
+/*
+TranscriptLabelled concatenates the data of chunks, prefixing each line
+with the label of its source: the label whose index matches Source, for
+example, "[out] " and "[err] ".  Sources lacking a label aren't prefixed.
+
+- A line split across several chunks is reassembled before being labelled,
+so it's placed in the transcript once it's complete.
+
+- A source's unterminated final line is appended after the complete lines,
+terminated by a newline, so it doesn't merge with another source's line.
+*/
+func TranscriptLabelled(chunks []CaptureChunk, labels ...string) string {
+	var tscpt strings.Builder
+	label := func(src int) string {
+		if src >= 0 && src < len(labels) {
+			return labels[src]
+		}
+		return ""
+	}
+	partial := make(map[int]string)
+	// sources in the order their unterminated lines began.
+	var order []int
+	for _, chunk := range chunks {
+		data := chunk.Data
+		for {
+			end := strings.IndexByte(data, '\n')
+			if end < 0 {
+				break
+			}
+			tscpt.WriteString(label(chunk.Source))
+			tscpt.WriteString(partial[chunk.Source])
+			tscpt.WriteString(data[:end+1])
+			partial[chunk.Source] = ""
+			data = data[end+1:]
+		}
+		if data == "" {
+			continue
+		}
+		if partial[chunk.Source] == "" {
+			order = append(order, chunk.Source)
+		}
+		partial[chunk.Source] += data
+	}
+	for _, src := range order {
+		if partial[src] != "" {
+			tscpt.WriteString(label(src) + partial[src] + "\n")
+			partial[src] = ""
+		}
+	}
+	return tscpt.String()
+}
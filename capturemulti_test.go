@@ -0,0 +1,54 @@
+package mckio
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CaptureMultiOrder(t *testing.T) {
+	assrt := assert.New(t)
+	var out, errf *os.File
+	mc, err := FileCaptureMulti(&out, &errf)
+	assrt.Nil(err)
+	fmt.Fprint(out, "starting\n")
+	// permit the capture of each write before the next
+	time.Sleep(10 * time.Millisecond)
+	fmt.Fprint(errf, "warning\n")
+	time.Sleep(10 * time.Millisecond)
+	fmt.Fprint(out, "done\n")
+	chunks, err := mc.End()
+	assrt.Nil(err)
+	assrt.Equal([]CaptureChunk{
+		{Source: 0, Data: "starting\n"},
+		{Source: 1, Data: "warning\n"},
+		{Source: 0, Data: "done\n"},
+	}, chunks)
+	assrt.Equal("starting\nwarning\ndone\n", Transcript(chunks))
+	assrt.Nil(out)
+	assrt.Nil(errf)
+}
+func Test_CaptureMultiStdio(t *testing.T) {
+	assrt := assert.New(t)
+	mc, err := FileCaptureMulti(&os.Stdout, &os.Stderr)
+	assrt.Nil(err)
+	chunks, err := mc.End()
+	assrt.Nil(err)
+	assrt.Empty(chunks)
+}
+func Test_TranscriptLabelled(t *testing.T) {
+	assrt := assert.New(t)
+	chunks := []CaptureChunk{
+		{Source: 0, Data: "starting\npro"},
+		{Source: 1, Data: "warning\n"},
+		{Source: 0, Data: "gress\ndone"},
+		{Source: 2, Data: "unlabelled\n"},
+		{Source: 1, Data: "failed"},
+	}
+	assrt.Equal("[out] starting\n[err] warning\n[out] progress\nunlabelled\n[out] done\n[err] failed\n",
+		TranscriptLabelled(chunks, "[out] ", "[err] "))
+	assrt.Equal("", TranscriptLabelled(nil, "[out] "))
+}
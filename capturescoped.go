@@ -0,0 +1,42 @@
+package mckio
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+// bounds the wait for a scoped capture's remaining output, so a wedged
+// capture can't hang the test's cleanup.
+const scopedEndTimeout = 5 * time.Second
+
+/*
+FileCaptureScoped starts capturing writes targeted to the variable
+referenced by 'osf' for the duration of the test 'tb'.  The capture is
+ended by tb.Cleanup, which also runs when the test fails, calls
+runtime.Goexit or panics, so the variable is always restored and output of
+subsequent tests isn't redirected.
+
+- A failure to start capturing fails the test immediately.
+
+- Calling End before the test completes is permitted.  Failures ending the
+capture during cleanup, like a capture wedged by a descriptor that remains
+open, are reported via tb.Errorf.
+*/
+func FileCaptureScoped(tb testing.TB, osf **os.File) *Capture {
+	tb.Helper()
+	capt, err := fileCapture(osf, nil, nil)
+	if err != nil {
+		tb.Fatalf("mckio: capture failed to start: %v", err)
+		return nil
+	}
+	tb.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), scopedEndTimeout)
+		defer cancel()
+		if _, err := capt.EndContext(ctx); err != nil {
+			tb.Errorf("%v", err)
+		}
+	})
+	return capt
+}
@@ -0,0 +1,45 @@
+package mckio
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CaptureScopedCleanup(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	var capt *Capture
+	t.Run("sub", func(t *testing.T) {
+		capt = FileCaptureScoped(t, &captFile)
+		fmt.Fprint(captFile, "line 1\n")
+	})
+	assrt.Nil(captFile)
+	output, err := capt.End()
+	assrt.Nil(err)
+	assrt.Equal("line 1\n", output)
+}
+func Test_CaptureScopedPanic(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	t.Run("sub", func(t *testing.T) {
+		defer func() { recover() }()
+		FileCaptureScoped(t, &captFile)
+		panic("aborted mid-capture")
+	})
+	assrt.Nil(captFile)
+}
+func Test_CaptureScopedEnded(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	t.Run("sub", func(t *testing.T) {
+		capt := FileCaptureScoped(t, &captFile)
+		fmt.Fprint(captFile, "line 1\n")
+		output, _ := capt.End()
+		assrt.Equal("line 1\n", output)
+		assrt.Nil(captFile)
+	})
+	assrt.Nil(captFile)
+}
@@ -0,0 +1,188 @@
+package mckio
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+/*
+TempCapture redirects write operations targeted to a file into a per-test
+temporary file.  It's aimed at capturing binary output, like generated
+images, that would otherwise be held in memory as huge strings and
+clutter failure logs.
+
+- Path exposes the temporary file's location, so it can be inspected or
+preserved when debugging.
+
+- ReaderAt lazily opens the temporary file for random access, loading
+nothing into memory until read.
+
+- The temporary file is removed and the redirected file restored when the
+test completes, via testing.TB's Cleanup.
+
+- Not concurrency safe with respect to the redirected variable, like
+FileCaptureStart.
+
+- Do not attempt to read from the redirected variable while it's captured.
+*/
+type TempCapture struct {
+	mu    sync.Mutex
+	osf   **os.File
+	orig  *os.File
+	wrt   *os.File
+	rdr   *os.File
+	path  string
+	ended bool
+}
+
+/*
+FileCaptureTemp starts capturing writes targeted to the variable referenced
+by 'osf' into a temporary file belonging to the test 'tb'.
+*/
+func FileCaptureTemp(tb testing.TB, osf **os.File) (*TempCapture, error) {
+	path := filepath.Join(tb.TempDir(), "capture")
+	wrt, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	tc := &TempCapture{osf: osf, orig: *osf, wrt: wrt, path: path}
+	*osf = wrt
+	tb.Cleanup(tc.cleanup)
+	return tc, nil
+}
+
+/*
+End terminates capturing and restores the variable to its original value.
+Calling it more than once is harmless.
+*/
+func (tc *TempCapture) End() error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if tc.ended {
+		return nil
+	}
+	tc.ended = true
+	*tc.osf = tc.orig
+	return tc.wrt.Close()
+}
+
+/*
+Path returns the location of the temporary file containing the capture.
+*/
+func (tc *TempCapture) Path() string {
+	return tc.path
+}
+
+/*
+ReaderAt returns random access to the captured content.  The temporary file
+is opened on first use and remains open until the test completes.  Content
+written after End isn't captured.
+*/
+func (tc *TempCapture) ReaderAt() (io.ReaderAt, error) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if tc.rdr == nil {
+		rdr, err := os.Open(tc.path)
+		if err != nil {
+			return nil, err
+		}
+		tc.rdr = rdr
+	}
+	return tc.rdr, nil
+}
+
+/*
+Size returns the number of bytes captured so far.
+*/
+func (tc *TempCapture) Size() (int64, error) {
+	info, err := os.Stat(tc.path)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+/*
+SpillCapture redirects write operations targeted to a file into a spool
+file, retaining output too large for memory.  Unlike TempCapture, it
+doesn't depend on a test, and the spool file's lifetime is controlled by
+the reader returned by End.
+
+- Not concurrency safe with respect to the redirected variable, like
+FileCaptureStart.
+
+- Do not attempt to read from the redirected variable while it's captured.
+*/
+type SpillCapture struct {
+	mu    sync.Mutex
+	osf   **os.File
+	orig  *os.File
+	spool *os.File
+	ended bool
+}
+
+/*
+FileCaptureSpill starts capturing writes targeted to the variable
+referenced by 'osf' into a spool file created in the default directory for
+temporary files.
+*/
+func FileCaptureSpill(osf **os.File) (*SpillCapture, error) {
+	spool, err := os.CreateTemp("", "mckio-capture-*")
+	if err != nil {
+		return nil, err
+	}
+	sc := &SpillCapture{osf: osf, orig: *osf, spool: spool}
+	*osf = spool
+	return sc, nil
+}
+
+/*
+End terminates capturing, restores the variable to its original value, and
+returns an io.ReadSeekCloser positioned at the start of the complete
+capture.  Closing it removes the spool file.  Calling End more than once
+returns the same reader.
+*/
+func (sc *SpillCapture) End() (io.ReadSeekCloser, error) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	rdr := spoolReader{sc.spool}
+	if sc.ended {
+		return rdr, nil
+	}
+	sc.ended = true
+	*sc.osf = sc.orig
+	if _, err := sc.spool.Seek(0, io.SeekStart); err != nil {
+		rdr.Close()
+		return nil, err
+	}
+	return rdr, nil
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+func (tc *TempCapture) cleanup() {
+	tc.End()
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if tc.rdr != nil {
+		tc.rdr.Close()
+	}
+	// testing removes the temporary directory and its file.
+}
+
+type spoolReader struct {
+	*os.File
+}
+
+func (sr spoolReader) Close() error {
+	err := sr.File.Close()
+	if rerr := os.Remove(sr.Name()); err == nil {
+		err = rerr
+	}
+	return err
+}
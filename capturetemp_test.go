@@ -0,0 +1,71 @@
+package mckio
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FileCaptureTempBinary(t *testing.T) {
+	assrt := assert.New(t)
+	orig := os.Stdout
+	tc, err := FileCaptureTemp(t, &os.Stdout)
+	assrt.Nil(err)
+	bin := []byte{0x89, 'P', 'N', 'G', 0x00, 0xff}
+	os.Stdout.Write(bin)
+	assrt.Nil(tc.End())
+	assrt.Equal(orig, os.Stdout)
+	sz, err := tc.Size()
+	assrt.Nil(err)
+	assrt.Equal(int64(len(bin)), sz)
+	ra, err := tc.ReaderAt()
+	assrt.Nil(err)
+	p := make([]byte, 3)
+	n, err := ra.ReadAt(p, 1)
+	assrt.Equal(3, n)
+	assrt.Equal(bin[1:4], p)
+	all, err := os.ReadFile(tc.Path())
+	assrt.Nil(err)
+	assrt.Equal(bin, all)
+}
+func Test_FileCaptureTempCleanupRestores(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	var path string
+	t.Run("capture", func(t *testing.T) {
+		tc, err := FileCaptureTemp(t, &captFile)
+		assrt.Nil(err)
+		assrt.NotNil(captFile)
+		path = tc.Path()
+		// subtest ends without calling End
+	})
+	assrt.Nil(captFile)
+	_, err := os.Stat(path)
+	assrt.True(os.IsNotExist(err))
+}
+func Test_FileCaptureSpill(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	sc, err := FileCaptureSpill(&captFile)
+	assrt.Nil(err)
+	chunk := bytes.Repeat([]byte{0x00, 0xff}, 32*1024)
+	for i := 0; i < 4; i++ {
+		captFile.Write(chunk)
+	}
+	rdr, err := sc.End()
+	assrt.Nil(err)
+	assrt.Nil(captFile)
+	all, err := io.ReadAll(rdr)
+	assrt.Nil(err)
+	assrt.Equal(bytes.Repeat(chunk, 4), all)
+	pos, err := rdr.Seek(-2, io.SeekEnd)
+	assrt.Nil(err)
+	assrt.Equal(int64(len(all)-2), pos)
+	path := rdr.(interface{ Name() string }).Name()
+	assrt.Nil(rdr.Close())
+	_, err = os.Stat(path)
+	assrt.True(os.IsNotExist(err))
+}
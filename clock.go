@@ -0,0 +1,396 @@
+package mckio
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+Clock supplies the time to mocks that sleep, throttle, or expire, so a test
+can replace the wall clock with a FakeClock and run timing dependent
+simulations instantly and deterministically.
+
+- Now returns the current time.
+
+- Sleep blocks until 'd' has elapsed.
+
+- After returns a channel receiving the current time once 'd' has elapsed.
+
+- NewTimer returns a Timer expiring once 'd' has elapsed, which, unlike
+After, can be stopped to release its resources before it expires.
+*/
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+	After(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+/*
+Timer is a single expiration created by Clock's NewTimer.
+
+- C returns the channel receiving the time once the timer expires.
+
+- Stop prevents the timer from expiring, returning false when it already
+expired or was stopped.
+*/
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+/*
+BehaviorClocker supplies the Clock a mock uses to measure and wait for
+time, replacing the wall clock.
+*/
+type BehaviorClocker interface {
+	BehaviorClock() Clock
+}
+
+/*
+SystemClock returns the Clock implemented by the time package.
+*/
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+/*
+FakeClock implements a Clock whose time only changes when the test calls
+Advance, or, in auto-advance mode, when all readers are blocked.  Sleep,
+After, and timers wait until the time moves to or beyond their expiration.
+
+- SetAutoAdvance steps the time to the earliest expiration whenever a
+Sleep, After, or NewTimer call is pending while every reader it watches is
+blocked in Read, so a test never sleeps on the wall clock yet still
+exercises the timeout branches of the code under test.  A channel returned
+by After and abandoned by its receiver remains pending until it expires,
+whereas a stopped Timer no longer is.
+
+- Waits reports every Sleep, After, and NewTimer call, in the order
+requested, so a test can assert pacing, like retries backing off 100ms,
+200ms, then 400ms.
+
+- FakeClock implements BehaviorClocker, so it can be passed directly as the
+behavior of a mock.
+
+FakeClock is concurrency safe.
+*/
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+	waits   []ClockWait
+	auto    bool
+	readers []blockReporter
+	polling bool
+}
+
+/*
+ClockWait is a single Sleep, After, or NewTimer call recorded by FakeClock.
+
+- Op - either "Sleep", "After", or "NewTimer".
+
+- Duration - the requested duration.
+
+- At - the fake time of the call.
+
+- Caller - the file and line of the call's site outside of mckio, like the
+code under test or the test itself.
+
+- Behavior - the mckio function waiting on behalf of Caller, like
+"stdin.BehaviorBlockBeforeEachRead" for NewConsole's delay.  Empty when
+Caller calls the clock directly.
+*/
+type ClockWait struct {
+	Op       string
+	Duration time.Duration
+	At       time.Time
+	Caller   string
+	Behavior string
+}
+
+/*
+NewFakeClock creates a FakeClock whose time starts at 'start'.
+*/
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+/*
+Now returns the fake time.
+*/
+func (fc *FakeClock) Now() time.Time {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.now
+}
+
+/*
+Sleep blocks until Advance moves the time 'd' beyond the time of the call.
+Returns immediately when 'd' isn't positive.
+*/
+func (fc *FakeClock) Sleep(d time.Duration) {
+	<-fc.wait("Sleep", d)
+}
+
+/*
+After returns a channel receiving the fake time once Advance moves the time
+'d' beyond the time of the call.  The channel receives immediately when 'd'
+isn't positive.
+*/
+func (fc *FakeClock) After(d time.Duration) <-chan time.Time {
+	return fc.wait("After", d)
+}
+
+/*
+NewTimer returns a Timer expiring once Advance moves the time 'd' beyond the
+time of the call.  The Timer expires immediately when 'd' isn't positive.
+*/
+func (fc *FakeClock) NewTimer(d time.Duration) Timer {
+	return fakeTimer{fc: fc, ch: fc.wait("NewTimer", d)}
+}
+
+/*
+Advance moves the time forward by 'd', releasing, in expiration order,
+the Sleep, After, and NewTimer calls expiring on or before the new time.
+*/
+func (fc *FakeClock) Advance(d time.Duration) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.now = fc.now.Add(d)
+	fc.release()
+}
+
+/*
+SetAutoAdvance enables auto-advance mode, stepping the time to the earliest
+expiration whenever a Sleep, After, or NewTimer call is pending and every
+reader in 'readers' is blocked in Read, either executing a blocking
+behavior, like NewConsole's delay, or awaiting a message, like an Rchan.
+Without readers, the time steps as soon as a call is pending.  'readers'
+replaces the readers watched by a previous call.
+
+- Each reader must be a pointer to an Rstrings, Rchan, or Rkeys, which
+report when their Read is blocked.  Panics otherwise.
+
+- A reader is considered blocked until its Read resumes, therefore, the
+time may step again before a reader released by the previous step runs.
+*/
+func (fc *FakeClock) SetAutoAdvance(readers ...io.Reader) {
+	watch := make([]blockReporter, len(readers))
+	for i, rdr := range readers {
+		br, ok := rdr.(blockReporter)
+		if !ok {
+			panic(fmt.Sprintf("mckio: FakeClock can't observe whether a %T is blocked", rdr))
+		}
+		watch[i] = br
+	}
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.auto = true
+	fc.readers = watch
+	fc.autoAdvance()
+}
+
+/*
+StopAutoAdvance disables auto-advance mode, so the time only changes when
+the test calls Advance.
+*/
+func (fc *FakeClock) StopAutoAdvance() {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.auto = false
+	fc.readers = nil
+}
+
+/*
+Waiters reports the number of Sleep, After, and NewTimer calls yet to expire,
+so a test can wait for the code under test to block before calling Advance.
+*/
+func (fc *FakeClock) Waiters() int {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return len(fc.waiters)
+}
+
+/*
+Waits returns a copy of the Sleep, After, and NewTimer calls recorded so far.
+*/
+func (fc *FakeClock) Waits() []ClockWait {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return append([]ClockWait(nil), fc.waits...)
+}
+
+/*
+BehaviorClock implements BehaviorClocker returning the FakeClock itself.
+*/
+func (fc *FakeClock) BehaviorClock() Clock {
+	return fc
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	t *time.Timer
+}
+
+func (st systemTimer) C() <-chan time.Time { return st.t.C }
+func (st systemTimer) Stop() bool          { return st.t.Stop() }
+
+type fakeTimer struct {
+	fc *FakeClock
+	ch <-chan time.Time
+}
+
+func (ft fakeTimer) C() <-chan time.Time {
+	return ft.ch
+}
+
+// removes the timer from the waiters, so it no longer counts toward
+// auto-advance.
+func (ft fakeTimer) Stop() bool {
+	ft.fc.mu.Lock()
+	defer ft.fc.mu.Unlock()
+	for i, w := range ft.fc.waiters {
+		if w.ch == ft.ch {
+			ft.fc.waiters = append(ft.fc.waiters[:i], ft.fc.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func (fc *FakeClock) wait(op string, d time.Duration) <-chan time.Time {
+	caller, behavior := clockCaller()
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.waits = append(fc.waits, ClockWait{
+		Op:       op,
+		Duration: d,
+		At:       fc.now,
+		Caller:   caller,
+		Behavior: behavior,
+	})
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- fc.now
+		return ch
+	}
+	fc.waiters = append(fc.waiters, fakeWaiter{at: fc.now.Add(d), ch: ch})
+	fc.autoAdvance()
+	return ch
+}
+
+// locates the first call site outside of mckio, considering mckio's own
+// tests outside, and the mckio function calling the clock on its behalf.
+func clockCaller() (caller, behavior string) {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	var pkg string
+	for {
+		frame, more := frames.Next()
+		name := frame.Function
+		if pkg == "" {
+			// the first frame is FakeClock.wait.
+			slash := strings.LastIndex(name, "/") + 1
+			pkg = name[:slash+strings.Index(name[slash:], ".")+1]
+		}
+		if !strings.HasPrefix(name, pkg) || strings.HasSuffix(frame.File, "_test.go") {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line), behavior
+		}
+		if behavior == "" && !strings.HasPrefix(name, pkg+"(*FakeClock)") {
+			behavior = strings.TrimPrefix(name, pkg)
+		}
+		if !more {
+			return "", behavior
+		}
+	}
+}
+
+// signals the waiters expiring on or before the current time.  the caller
+// must hold the lock.
+func (fc *FakeClock) release() {
+	sort.SliceStable(fc.waiters, func(i, j int) bool {
+		return fc.waiters[i].at.Before(fc.waiters[j].at)
+	})
+	var n int
+	for n < len(fc.waiters) && !fc.waiters[n].at.After(fc.now) {
+		fc.waiters[n].ch <- fc.now
+		n++
+	}
+	fc.waiters = append(fc.waiters[:0], fc.waiters[n:]...)
+}
+
+// implemented by the readers recording whether their Read is blocked.
+type blockReporter interface {
+	readBlocked() bool
+}
+
+// steps the time to the earliest expiration once a call waits while every
+// watched reader is blocked.  as a reader can block without calling the
+// clock, for example, awaiting a channel, its state is polled until it
+// blocks.  the caller must hold the lock.
+func (fc *FakeClock) autoAdvance() {
+	if !fc.auto || len(fc.waiters) < 1 {
+		return
+	}
+	for _, br := range fc.readers {
+		if !br.readBlocked() {
+			if !fc.polling {
+				fc.polling = true
+				go fc.poll()
+			}
+			return
+		}
+	}
+	earliest := fc.waiters[0].at
+	for _, w := range fc.waiters[1:] {
+		if w.at.Before(earliest) {
+			earliest = w.at
+		}
+	}
+	fc.now = earliest
+	fc.release()
+}
+
+// reevaluates auto-advance after a pause, so readers that blocked in the
+// meantime are observed.
+func (fc *FakeClock) poll() {
+	time.Sleep(time.Millisecond)
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.polling = false
+	fc.autoAdvance()
+}
+
+// returns the Clock supplied by BehaviorClocker, or the system clock when
+// undefined.
+func clockOf(behavior interface{}) Clock {
+	if bc, ok := behavior.(BehaviorClocker); ok {
+		if c := bc.BehaviorClock(); c != nil {
+			return c
+		}
+	}
+	return systemClock{}
+}
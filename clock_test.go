@@ -0,0 +1,203 @@
+package mckio
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FakeClockAdvance(t *testing.T) {
+	assrt := assert.New(t)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	fc := NewFakeClock(start)
+	assrt.Equal(start, fc.Now())
+	late := fc.After(2 * time.Second)
+	early := fc.After(time.Second)
+	assrt.Equal(2, fc.Waiters())
+	fc.Advance(500 * time.Millisecond)
+	select {
+	case <-early:
+		assrt.Fail("expired before its time")
+	default:
+	}
+	fc.Advance(time.Second)
+	assrt.Equal(start.Add(1500*time.Millisecond), <-early)
+	assrt.Equal(1, fc.Waiters())
+	fc.Advance(time.Second)
+	assrt.Equal(start.Add(2500*time.Millisecond), <-late)
+	assrt.Equal(0, fc.Waiters())
+	assrt.Equal(fc.Now(), <-fc.After(0))
+}
+func Test_FakeClockSleep(t *testing.T) {
+	assrt := assert.New(t)
+	fc := NewFakeClock(time.Time{})
+	done := make(chan struct{})
+	go func() {
+		fc.Sleep(time.Minute)
+		close(done)
+	}()
+	fakeClockWait(fc, 1)
+	fc.Advance(time.Minute)
+	<-done
+	assrt.Equal(time.Time{}.Add(time.Minute), fc.Now())
+}
+func Test_ConsoleClock(t *testing.T) {
+	assrt := assert.New(t)
+	fc := NewFakeClock(time.Time{})
+	rdr := NewConsoleClock([]string{"one"}, fc)
+	got := make(chan string)
+	go func() {
+		p := make([]byte, 10)
+		n, _ := rdr.Read(p)
+		got <- string(p[:n])
+	}()
+	fakeClockWait(fc, 1)
+	fc.Advance(time.Second)
+	assrt.Equal("one\n", <-got)
+}
+func Test_WslowClock(t *testing.T) {
+	assrt := assert.New(t)
+	fc := NewFakeClock(time.Time{})
+	var buf bytes.Buffer
+	ws := NewWslow(&buf, 10, fc)
+	done := make(chan struct{})
+	go func() {
+		ws.Write([]byte("ab"))
+		close(done)
+	}()
+	fakeClockWait(fc, 1)
+	fc.Advance(100 * time.Millisecond)
+	fakeClockWait(fc, 1)
+	fc.Advance(100 * time.Millisecond)
+	<-done
+	assrt.Equal("ab", buf.String())
+}
+func Test_RchanDeadlineClock(t *testing.T) {
+	assrt := assert.New(t)
+	fc := NewFakeClock(time.Time{})
+	rdr := NewChanBehavior(make(chan string), fc)
+	rdr.SetReadDeadline(fc.Now().Add(time.Hour))
+	done := make(chan error)
+	go func() {
+		_, err := rdr.Read(make([]byte, 10))
+		done <- err
+	}()
+	fakeClockWait(fc, 1)
+	fc.Advance(time.Hour)
+	assrt.Equal(os.ErrDeadlineExceeded, <-done)
+}
+func Test_SystemClock(t *testing.T) {
+	assrt := assert.New(t)
+	clock := SystemClock()
+	before := time.Now()
+	clock.Sleep(time.Millisecond)
+	<-clock.After(time.Millisecond)
+	tmr := clock.NewTimer(time.Millisecond)
+	<-tmr.C()
+	assrt.False(tmr.Stop())
+	assrt.True(clock.Now().Sub(before) >= 3*time.Millisecond)
+	assrt.True(clock.NewTimer(time.Hour).Stop())
+}
+func Test_FakeClockTimer(t *testing.T) {
+	assrt := assert.New(t)
+	fc := NewFakeClock(time.Time{})
+	stopped := fc.NewTimer(time.Second)
+	expires := fc.NewTimer(2 * time.Second)
+	assrt.Equal(2, fc.Waiters())
+	assrt.True(stopped.Stop())
+	assrt.False(stopped.Stop())
+	assrt.Equal(1, fc.Waiters())
+	fc.Advance(2 * time.Second)
+	assrt.Equal(time.Time{}.Add(2*time.Second), <-expires.C())
+	assrt.False(expires.Stop())
+	select {
+	case <-stopped.C():
+		assrt.Fail("stopped timer expired")
+	default:
+	}
+	assrt.Equal("NewTimer", fc.Waits()[0].Op)
+}
+
+// waits until 'n' calls wait on the fake clock.
+func fakeClockWait(fc *FakeClock, n int) {
+	for fc.Waiters() < n {
+		time.Sleep(time.Millisecond)
+	}
+}
+func Test_FakeClockAutoAdvance(t *testing.T) {
+	assrt := assert.New(t)
+	fc := NewFakeClock(time.Time{})
+	fc.SetAutoAdvance()
+	fc.Sleep(time.Hour)
+	assrt.Equal(time.Time{}.Add(time.Hour), fc.Now())
+	rdr := NewConsoleClock([]string{"a"}, fc)
+	p := make([]byte, 10)
+	n, err := rdr.Read(p)
+	assrt.Nil(err)
+	assrt.Equal("a\n", string(p[:n]))
+	assrt.Equal(time.Time{}.Add(time.Hour+time.Second), fc.Now())
+}
+func Test_FakeClockAutoAdvanceReaders(t *testing.T) {
+	assrt := assert.New(t)
+	fc := NewFakeClock(time.Time{})
+	msgs := make(chan string)
+	rdr := NewChan(msgs)
+	fc.SetAutoAdvance(&rdr)
+	done := make(chan time.Time)
+	go func() {
+		<-fc.After(time.Second)
+		done <- fc.Now()
+	}()
+	fakeClockWait(fc, 1)
+	// the reader isn't blocked, so the time doesn't advance.
+	time.Sleep(10 * time.Millisecond)
+	assrt.Equal(1, fc.Waiters())
+	read := make(chan string)
+	go func() {
+		p := make([]byte, 10)
+		n, _ := rdr.Read(p)
+		read <- string(p[:n])
+	}()
+	assrt.Equal(time.Time{}.Add(time.Second), <-done)
+	msgs <- "a"
+	assrt.Equal("a", <-read)
+	fc.StopAutoAdvance()
+	go fc.Sleep(time.Second)
+	fakeClockWait(fc, 1)
+	assrt.Equal(time.Time{}.Add(time.Second), fc.Now())
+}
+func Test_FakeClockAutoAdvanceReaderPanics(t *testing.T) {
+	assrt := assert.New(t)
+	assrt.Panics(func() { NewFakeClock(time.Time{}).SetAutoAdvance(strings.NewReader("")) })
+}
+func Test_FakeClockWaits(t *testing.T) {
+	assrt := assert.New(t)
+	fc := NewFakeClock(time.Time{})
+	fc.SetAutoAdvance()
+	for backoff := 100 * time.Millisecond; backoff <= 400*time.Millisecond; backoff *= 2 {
+		fc.Sleep(backoff)
+	}
+	<-fc.After(time.Second)
+	rdr := NewConsoleClock([]string{"a"}, fc)
+	rdr.Read(make([]byte, 10))
+	waits := fc.Waits()
+	if !assrt.Len(waits, 5) {
+		return
+	}
+	var durs []time.Duration
+	for _, w := range waits[:3] {
+		durs = append(durs, w.Duration)
+		assrt.Equal("Sleep", w.Op)
+		assrt.Equal("", w.Behavior)
+		assrt.Contains(w.Caller, "clock_test.go:")
+	}
+	assrt.Equal([]time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond}, durs)
+	assrt.Equal(time.Time{}.Add(300*time.Millisecond), waits[2].At)
+	assrt.Equal("After", waits[3].Op)
+	assrt.Equal("stdin.BehaviorBlockBeforeEachRead", waits[4].Behavior)
+	assrt.Contains(waits[4].Caller, "clock_test.go:")
+}
@@ -0,0 +1,112 @@
+package mckio
+
+import (
+	"os"
+	"sync"
+	"testing"
+)
+
+/*
+ConsoleHarness replaces os.Stdin, os.Stdout, and os.Stderr for the duration
+of a test, so main()-style interactive programs can be tested end to end.
+
+- os.Stdin is replaced by a pipe delivering the scripted input, one line per
+string, followed by end of file.
+
+- os.Stdout and os.Stderr are captured together, like FileCaptureMulti, so
+the transcript preserves the interleaving of their output.
+
+- The original streams are restored by End, or by the test's cleanup when
+End isn't called, even when the test panics.
+
+ConsoleHarness isn't concurrency safe, as it replaces package-level
+variables.  Don't use it in tests running in parallel.
+*/
+type ConsoleHarness struct {
+	tb      testing.TB
+	origIn  *os.File
+	stopIn  func()
+	capture *MultiCapture
+	endOnce sync.Once
+	chunks  []CaptureChunk
+}
+
+/*
+NewConsoleHarness replaces the standard streams, feeding 'input' to
+os.Stdin.  Failure to establish the replacements fails the test.
+*/
+func NewConsoleHarness(tb testing.TB, input []string) *ConsoleHarness {
+	tb.Helper()
+	lines := NewRstrings(input, harnessDelim{})
+	stdin, stopIn, err := stdinPipe(&lines)
+	if err != nil {
+		tb.Fatalf("mckio: unable to replace os.Stdin: %v", err)
+	}
+	capture, err := FileCaptureMulti(&os.Stdout, &os.Stderr)
+	if err != nil {
+		stopIn()
+		tb.Fatalf("mckio: unable to capture os.Stdout and os.Stderr: %v", err)
+	}
+	ch := &ConsoleHarness{tb: tb, origIn: os.Stdin, stopIn: stopIn, capture: capture}
+	os.Stdin = stdin
+	tb.Cleanup(func() { ch.End() })
+	return ch
+}
+
+/*
+RunConsole executes fn within a ConsoleHarness feeding it 'input', then
+returns the transcript of its output.
+*/
+func RunConsole(tb testing.TB, input []string, fn func()) string {
+	tb.Helper()
+	return NewConsoleHarness(tb, input).Run(fn)
+}
+
+/*
+Run executes fn, then ends the harness returning the transcript.  The
+streams are restored even when fn panics.
+*/
+func (ch *ConsoleHarness) Run(fn func()) string {
+	defer ch.End()
+	fn()
+	return ch.End()
+}
+
+/*
+End restores the standard streams and returns the transcript: the output
+written to os.Stdout and os.Stderr merged in the order it was captured.
+Errors capturing the output fail the test.  Calling it more than once
+returns the same transcript.
+*/
+func (ch *ConsoleHarness) End() string {
+	ch.endOnce.Do(func() {
+		os.Stdin = ch.origIn
+		// ends the goroutine writing the input to the pipe.
+		ch.stopIn()
+		chunks, err := ch.capture.End()
+		if err != nil {
+			ch.tb.Errorf("mckio: capturing console output failed: %v", err)
+		}
+		ch.chunks = chunks
+	})
+	return Transcript(ch.chunks)
+}
+
+/*
+Chunks returns the transcript's chunks, each tagged with its source: 0 for
+os.Stdout and 1 for os.Stderr.  It's empty until End is called.
+*/
+func (ch *ConsoleHarness) Chunks() []CaptureChunk {
+	return ch.chunks
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+// terminates each line of scripted input with a newline.
+type harnessDelim struct{}
+
+func (harnessDelim) BehaviorDelim() []byte {
+	return []byte{'\n'}
+}
@@ -0,0 +1,37 @@
+package mckio
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ConsoleHarness(t *testing.T) {
+	assrt := assert.New(t)
+	origIn, origOut, origErr := os.Stdin, os.Stdout, os.Stderr
+	transcript := RunConsole(t, []string{"alice", "bob"}, func() {
+		scn := bufio.NewScanner(os.Stdin)
+		for fmt.Print("name? "); scn.Scan(); fmt.Print("name? ") {
+			fmt.Printf("hello %s\n", scn.Text())
+		}
+	})
+	assrt.Equal("name? hello alice\nname? hello bob\nname? ", transcript)
+	assrt.Equal(origIn, os.Stdin)
+	assrt.Equal(origOut, os.Stdout)
+	assrt.Equal(origErr, os.Stderr)
+}
+func Test_ConsoleHarnessChunks(t *testing.T) {
+	assrt := assert.New(t)
+	origIn := os.Stdin
+	var ch *ConsoleHarness
+	t.Run("sub", func(t *testing.T) {
+		ch = NewConsoleHarness(t, nil)
+		fmt.Fprint(os.Stderr, "warning\n")
+		// restored by the test's cleanup
+	})
+	assrt.Equal(origIn, os.Stdin)
+	assrt.Equal([]CaptureChunk{{Source: 1, Data: "warning\n"}}, ch.Chunks())
+}
@@ -0,0 +1,66 @@
+package mckio
+
+import (
+	"unicode/utf16"
+)
+
+/*
+NewConsoleWindows simulates an io.Reader on os.Stdin of a Windows console,
+so cross-platform input parsing can be tested for Windows behavior from any
+OS.  Like NewConsole, it blocks 1 second before each read and blocks after
+exhausting the provided strings, however, each string element ends with a
+"\r\n" delimiter.
+
+The following behavior of NewConsoleWindows can be configured:
+
+- BehaviorUTF16Encoder (optional) - specifies whether the string elements
+and their delimiters are encoded as UTF-16LE, like the input read from the
+console by wide character APIs.  When undefined - the elements are read
+unchanged.
+
+- BehaviorClocker (optional) - supplies the Clock measuring the delay
+before each read.  When undefined - uses the system clock.
+*/
+func NewConsoleWindows(cmdLns []string, behavior interface{}) (rdr Rstrings) {
+	con := stdinWindows{stdin: stdin{clock: clockOf(behavior)}, delim: []byte("\r\n")}
+	if bue, ok := behavior.(BehaviorUTF16Encoder); ok && bue.BehaviorUTF16Encode() {
+		con.delim = utf16LE("\r\n")
+		encoded := make([]string, len(cmdLns))
+		for i, ln := range cmdLns {
+			encoded[i] = string(utf16LE(ln))
+		}
+		cmdLns = encoded
+	}
+	return NewRstrings(cmdLns, con)
+}
+
+/*
+BehaviorUTF16Encoder specifies whether a mock encodes its content as
+UTF-16LE.
+*/
+type BehaviorUTF16Encoder interface {
+	BehaviorUTF16Encode() bool
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+type stdinWindows struct {
+	stdin
+	delim []byte
+}
+
+func (s stdinWindows) BehaviorDelim() []byte {
+	return s.delim
+}
+
+// encodes 's' as UTF-16 code units in little endian byte order.
+func utf16LE(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	b := make([]byte, 0, 2*len(units))
+	for _, u := range units {
+		b = append(b, byte(u), byte(u>>8))
+	}
+	return b
+}
@@ -0,0 +1,42 @@
+package mckio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type windowsConsole struct {
+	*FakeClock
+	utf16 bool
+}
+
+func (wc windowsConsole) BehaviorUTF16Encode() bool {
+	return wc.utf16
+}
+func Test_ConsoleWindows(t *testing.T) {
+	assrt := assert.New(t)
+	fc := NewFakeClock(time.Time{})
+	rdr := NewConsoleWindows([]string{"dir", "exit"}, windowsConsole{FakeClock: fc})
+	fc.SetAutoAdvance(&rdr)
+	p := make([]byte, 20)
+	n, err := rdr.Read(p)
+	assrt.Nil(err)
+	assrt.Equal("dir\r\nexit\r\n", string(p[:n]))
+	assrt.Equal(time.Time{}.Add(time.Second), fc.Now())
+}
+func Test_ConsoleWindowsUTF16(t *testing.T) {
+	assrt := assert.New(t)
+	fc := NewFakeClock(time.Time{})
+	rdr := NewConsoleWindows([]string{"é€"}, windowsConsole{FakeClock: fc, utf16: true})
+	fc.SetAutoAdvance(&rdr)
+	p := make([]byte, 20)
+	n, err := rdr.Read(p)
+	assrt.Nil(err)
+	assrt.Equal([]byte{0xe9, 0x00, 0xac, 0x20, '\r', 0x00, '\n', 0x00}, p[:n])
+}
+func Test_UTF16LESurrogate(t *testing.T) {
+	assrt := assert.New(t)
+	assrt.Equal([]byte{0x3d, 0xd8, 0x00, 0xde}, utf16LE("\U0001F600"))
+}
@@ -0,0 +1,8 @@
+package mckio
+
+import "syscall"
+
+// several linux architectures lack dup2, so rely on dup3 instead.
+func dup2(oldfd int, newfd int) error {
+	return syscall.Dup3(oldfd, newfd, 0)
+}
@@ -0,0 +1,99 @@
+package mckio
+
+import "time"
+
+/*
+DuplexEnd is one end of an in-memory full duplex connection created by
+NewDuplex.  Bytes written to one end become readable from the other.
+
+- Close ends the stream written by this end, so the peer's Read returns
+io.EOF after consuming the bytes written before Close.  This end can
+continue to read.
+
+- DuplexEnd is concurrency safe: one goroutine may read while another writes.
+*/
+type DuplexEnd struct {
+	in  *Filter
+	out *Filter
+}
+
+/*
+NewDuplex creates a pair of connected ends, each implementing
+io.ReadWriteCloser.  Each direction is a Filter, so its behavior can be
+configured using the behaviors accepted by NewFilter.  They're applied to
+both directions.
+*/
+func NewDuplex(behavior interface{}) (a DuplexEnd, b DuplexEnd) {
+	ab := NewFilter(nil, behavior)
+	ba := NewFilter(nil, behavior)
+	return DuplexEnd{in: ba, out: ab}, DuplexEnd{in: ab, out: ba}
+}
+
+/*
+Read returns bytes written by the peer end.
+*/
+func (de DuplexEnd) Read(p []byte) (int, error) {
+	return de.in.Read(p)
+}
+
+/*
+Write sends bytes to the peer end.
+*/
+func (de DuplexEnd) Write(p []byte) (int, error) {
+	return de.out.Write(p)
+}
+
+/*
+Close signals the end of the stream written to the peer.
+*/
+func (de DuplexEnd) Close() error {
+	return de.out.Close()
+}
+
+/*
+NewEchoPeer creates a duplex connection whose far end is serviced by an
+echo server.  Every chunk read by the echo server is returned to the client
+end after an optional delay and mutation.
+
+- mutate (optional) - transforms each chunk before it's echoed.  When nil -
+the chunk is echoed unchanged.
+
+- delay - duration the echo server waits before echoing each chunk.
+
+The echo server terminates, closing its end, once the client end is closed.
+
+The following behavior of NewEchoPeer can be configured:
+
+- BehaviorClocker (optional) - supplies the Clock measuring the delay.
+When undefined - uses the system clock.
+*/
+func NewEchoPeer(mutate func(p []byte) []byte, delay time.Duration, behavior interface{}) (client DuplexEnd) {
+	client, server := NewDuplex(nil)
+	go echoServe(server, mutate, delay, clockOf(behavior))
+	return client
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+func echoServe(server DuplexEnd, mutate func(p []byte) []byte, delay time.Duration, clock Clock) {
+	defer server.Close()
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := server.Read(buf)
+		if n > 0 {
+			clock.Sleep(delay)
+			chunk := append([]byte(nil), buf[:n]...)
+			if mutate != nil {
+				chunk = mutate(chunk)
+			}
+			if _, errw := server.Write(chunk); errw != nil {
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package mckio
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DuplexBothDirections(t *testing.T) {
+	assrt := assert.New(t)
+	a, b := NewDuplex(nil)
+	go func() {
+		a.Write([]byte("ping"))
+		a.Close()
+	}()
+	fromA, err := io.ReadAll(b)
+	assrt.Nil(err)
+	assrt.Equal("ping", string(fromA))
+	// b's outbound stream remains open after a closes its own
+	b.Write([]byte("pong"))
+	b.Close()
+	fromB, err := io.ReadAll(a)
+	assrt.Nil(err)
+	assrt.Equal("pong", string(fromB))
+}
+func Test_EchoPeerRoundTrip(t *testing.T) {
+	assrt := assert.New(t)
+	client := NewEchoPeer(bytes.ToUpper, time.Millisecond, nil)
+	p := make([]byte, 16)
+	for _, msg := range []string{"hello", "world"} {
+		client.Write([]byte(msg))
+		sz, err := io.ReadFull(client, p[:len(msg)])
+		assrt.Nil(err)
+		assrt.Equal(string(bytes.ToUpper([]byte(msg))), string(p[:sz]))
+	}
+	client.Close()
+	sz, err := client.Read(p)
+	assrt.Zero(sz)
+	assrt.IsType(io.EOF, err)
+}
+func Test_EchoPeerClock(t *testing.T) {
+	assrt := assert.New(t)
+	fc := NewFakeClock(time.Time{})
+	client := NewEchoPeer(nil, time.Hour, fc)
+	client.Write([]byte("ping"))
+	fakeClockWait(fc, 1)
+	fc.Advance(time.Hour)
+	p := make([]byte, 4)
+	sz, err := io.ReadFull(client, p)
+	assrt.Nil(err)
+	assrt.Equal("ping", string(p[:sz]))
+	client.Close()
+}
@@ -0,0 +1,143 @@
+package mckio
+
+import (
+	"io"
+	"sync"
+)
+
+/*
+DuplexConsole pairs a simulated stdin with a captured stdout as a single
+fixture for interactive CLI functions accepting (in io.Reader, out
+io.Writer).
+
+- Reader supplies the input lines, each terminated by a newline, then
+returns io.EOF.  Like a terminal in cooked mode, a Read returns at most
+one line.  Unlike NewConsole, reads don't block.
+
+- SetRaw switches the Reader between cooked mode, the default, and raw mode,
+where each Read returns a single byte as soon as it's typed, without
+echoing it to the transcript, like a terminal whose termios disables
+ICANON and ECHO.  The mode may be switched between Reads, like a program
+toggling raw mode.
+
+- Writer captures the output, reported by Output.
+
+- Transcript interleaves the input consumed with the output written, in
+the order they occurred, resembling the terminal session a user would see.
+
+- DuplexConsole is concurrency safe.
+*/
+type DuplexConsole struct {
+	mu         sync.Mutex
+	in         []string
+	pos        int
+	out        []byte
+	transcript []byte
+	raw        bool
+}
+
+/*
+NewDuplexConsole creates a console whose Reader supplies 'input' as lines.
+*/
+func NewDuplexConsole(input []string) *DuplexConsole {
+	in := make([]string, len(input))
+	for i, ln := range input {
+		in[i] = ln + "\n"
+	}
+	return &DuplexConsole{in: in}
+}
+
+/*
+Reader returns the io.Reader simulating stdin.
+*/
+func (dc *DuplexConsole) Reader() io.Reader {
+	return consoleReader{dc}
+}
+
+/*
+SetRaw selects raw mode when 'raw' is true, otherwise cooked mode.
+*/
+func (dc *DuplexConsole) SetRaw(raw bool) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.raw = raw
+}
+
+/*
+Raw reports whether the console is in raw mode.
+*/
+func (dc *DuplexConsole) Raw() bool {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	return dc.raw
+}
+
+/*
+Writer returns the io.Writer capturing stdout.
+*/
+func (dc *DuplexConsole) Writer() io.Writer {
+	return consoleWriter{dc}
+}
+
+/*
+Output returns the output written so far.
+*/
+func (dc *DuplexConsole) Output() string {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	return string(dc.out)
+}
+
+/*
+Transcript returns the input read and output written so far, interleaved
+in the order they occurred.
+*/
+func (dc *DuplexConsole) Transcript() string {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	return string(dc.transcript)
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+type consoleReader struct {
+	dc *DuplexConsole
+}
+
+func (cr consoleReader) Read(p []byte) (int, error) {
+	cr.dc.mu.Lock()
+	defer cr.dc.mu.Unlock()
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if len(cr.dc.in) == 0 {
+		return 0, io.EOF
+	}
+	if cr.dc.raw {
+		p = p[:1]
+	}
+	n := copy(p, cr.dc.in[0][cr.dc.pos:])
+	if !cr.dc.raw {
+		// echo of cooked input
+		cr.dc.transcript = append(cr.dc.transcript, p[:n]...)
+	}
+	cr.dc.pos += n
+	if cr.dc.pos == len(cr.dc.in[0]) {
+		cr.dc.in, cr.dc.pos = cr.dc.in[1:], 0
+	}
+	return n, nil
+}
+
+type consoleWriter struct {
+	dc *DuplexConsole
+}
+
+func (cw consoleWriter) Write(p []byte) (int, error) {
+	cw.dc.mu.Lock()
+	defer cw.dc.mu.Unlock()
+	cw.dc.out = append(cw.dc.out, p...)
+	cw.dc.transcript = append(cw.dc.transcript, p...)
+	return len(p), nil
+}
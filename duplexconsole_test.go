@@ -0,0 +1,58 @@
+package mckio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DuplexConsoleSession(t *testing.T) {
+	assrt := assert.New(t)
+	dc := NewDuplexConsole([]string{"alice", "42"})
+	assrt.Nil(greet(dc.Reader(), dc.Writer()))
+	assrt.Equal("name: age: hello alice, 42\n", dc.Output())
+	assrt.Equal("name: alice\nage: 42\nhello alice, 42\n", dc.Transcript())
+}
+func Test_DuplexConsoleEOF(t *testing.T) {
+	assrt := assert.New(t)
+	dc := NewDuplexConsole([]string{"alice"})
+	assrt.Equal(io.EOF, greet(dc.Reader(), dc.Writer()))
+	assrt.Equal("name: alice\nage: ", dc.Transcript())
+}
+
+// interactive function under test.
+func greet(in io.Reader, out io.Writer) error {
+	scan := bufio.NewScanner(in)
+	var answers []string
+	for _, prompt := range []string{"name: ", "age: "} {
+		fmt.Fprint(out, prompt)
+		if !scan.Scan() {
+			return io.EOF
+		}
+		answers = append(answers, scan.Text())
+	}
+	fmt.Fprintf(out, "hello %s, %s\n", answers[0], answers[1])
+	return nil
+}
+func Test_DuplexConsoleRaw(t *testing.T) {
+	assrt := assert.New(t)
+	dc := NewDuplexConsole([]string{"yes", "name"})
+	dc.SetRaw(true)
+	assrt.True(dc.Raw())
+	p := make([]byte, 16)
+	n, _ := dc.Reader().Read(p)
+	assrt.Equal("y", string(p[:n]))
+	n, _ = dc.Reader().Read(p)
+	assrt.Equal("e", string(p[:n]))
+	fmt.Fprint(dc.Writer(), "> ")
+	// program restores cooked mode mid-line
+	dc.SetRaw(false)
+	n, _ = dc.Reader().Read(p)
+	assrt.Equal("s\n", string(p[:n]))
+	n, _ = dc.Reader().Read(p)
+	assrt.Equal("name\n", string(p[:n]))
+	assrt.Equal("> s\nname\n", dc.Transcript())
+}
@@ -0,0 +1,110 @@
+package mckio
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+/*
+Excerpt formats captured content included in failure messages so they remain
+readable.  Raw terminal output often contains escape sequences and other
+control characters that garble the report, while very long content buries
+the relevant portion.
+
+- Head, Tail - number of bytes retained from the start and end of content
+exceeding Head+Tail bytes.  The omitted middle is replaced by a marker
+reporting its size.  When both are zero - content isn't truncated.
+
+- Raw - when true, control characters aren't escaped.
+
+Newlines and tabs are never escaped, so multi-line transcripts retain their
+layout.  Other control characters and invalid UTF-8 bytes are rendered
+using Go escape notation, for example, "\x1b" for ESC.
+*/
+type Excerpt struct {
+	Head int
+	Tail int
+	Raw  bool
+}
+
+/*
+SetFailureExcerpt replaces the Excerpt formatting captured content reported
+by mckio's assertions and failure messages, returning the one it replaced
+so a test can restore it.  Initially, content exceeding 1024 bytes retains
+512 bytes from its start and end.  The setting is shared by every test in
+the process, so avoid changing it from tests running in parallel.
+*/
+func SetFailureExcerpt(ex Excerpt) (prior Excerpt) {
+	failureExcerpt.mu.Lock()
+	defer failureExcerpt.mu.Unlock()
+	prior = failureExcerpt.ex
+	failureExcerpt.ex = ex
+	return prior
+}
+
+/*
+Format returns the excerpt of content.
+*/
+func (ex Excerpt) Format(content string) string {
+	if ex.Head+ex.Tail > 0 && len(content) > ex.Head+ex.Tail {
+		head := content[:runeFloor(content, ex.Head)]
+		tail := content[runeFloor(content, len(content)-ex.Tail):]
+		omitted := len(content) - len(head) - len(tail)
+		return ex.escape(head) + fmt.Sprintf("\n... [%d bytes omitted] ...\n", omitted) + ex.escape(tail)
+	}
+	return ex.escape(content)
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+var failureExcerpt = struct {
+	mu sync.Mutex
+	ex Excerpt
+}{ex: Excerpt{Head: 512, Tail: 512}}
+
+func (ex Excerpt) escape(content string) string {
+	if ex.Raw {
+		return content
+	}
+	var esc strings.Builder
+	for i := 0; i < len(content); {
+		r, size := utf8.DecodeRuneInString(content[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&esc, `\x%02x`, content[i])
+		case r == '\n' || r == '\t':
+			esc.WriteRune(r)
+		case r == '\r':
+			esc.WriteString(`\r`)
+		case unicode.IsControl(r) && r < 0x100:
+			fmt.Fprintf(&esc, `\x%02x`, r)
+		case unicode.IsControl(r):
+			fmt.Fprintf(&esc, `\u%04x`, r)
+		default:
+			esc.WriteString(content[i : i+size])
+		}
+		i += size
+	}
+	return esc.String()
+}
+
+// adjusts offset backward to the start of the rune containing it.
+func runeFloor(s string, offset int) int {
+	for offset > 0 && offset < len(s) && !utf8.RuneStart(s[offset]) {
+		offset--
+	}
+	return offset
+}
+
+// formats content reported by failure messages.
+func excerpt(content string) string {
+	failureExcerpt.mu.Lock()
+	ex := failureExcerpt.ex
+	failureExcerpt.mu.Unlock()
+	return ex.Format(content)
+}
@@ -0,0 +1,35 @@
+package mckio
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ExcerptEscape(t *testing.T) {
+	assrt := assert.New(t)
+	ex := Excerpt{}
+	content := "\x1b[32mok\x1b[0m\r\nline 2\t\x00\xff€"
+	assrt.Equal(`\x1b[32mok\x1b[0m\r`+"\nline 2\t"+`\x00\xff€`, ex.Format(content))
+	ex.Raw = true
+	assrt.Equal("\x1b[32mok", ex.Format("\x1b[32mok"))
+}
+func Test_ExcerptTruncate(t *testing.T) {
+	assrt := assert.New(t)
+	ex := Excerpt{Head: 4, Tail: 3}
+	content := "head" + strings.Repeat("x", 100) + "end"
+	assrt.Equal("head\n... [100 bytes omitted] ...\nend", ex.Format(content))
+	// short content isn't truncated
+	assrt.Equal("headend", ex.Format("headend"))
+	// truncation respects rune boundaries
+	ex = Excerpt{Head: 2, Tail: 1}
+	assrt.Equal("a\n... [4 bytes omitted] ...\nb", ex.Format("a€xb"))
+}
+func Test_SetFailureExcerpt(t *testing.T) {
+	assrt := assert.New(t)
+	prior := SetFailureExcerpt(Excerpt{Head: 1, Tail: 1, Raw: true})
+	defer SetFailureExcerpt(prior)
+	assrt.Equal(Excerpt{Head: 512, Tail: 512}, prior)
+	assrt.Equal("a\n... [2 bytes omitted] ...\nd", excerpt("a\x1bcd"))
+}
@@ -0,0 +1,235 @@
+package mckio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+/*
+ExpectStep is a step of an expect-style script conducting a dialog with the
+code under test: Expect awaits output, Send types input, and ExpectEOF
+awaits the end of output.  Run the script using RunExpect or ExpectStdio.
+*/
+type ExpectStep struct {
+	kind    int
+	match   WriteMatcher
+	line    string
+	timeout time.Duration
+}
+
+/*
+Expect awaits output satisfying 'm', like ExpectContains or ExpectRegexp.
+The output considered is that written since the output matched by the
+previous Expect step.
+*/
+func Expect(m WriteMatcher) ExpectStep {
+	return ExpectStep{kind: stepExpect, match: m}
+}
+
+/*
+Send types 'line' followed by a newline.
+*/
+func Send(line string) ExpectStep {
+	return ExpectStep{kind: stepSend, line: line}
+}
+
+/*
+ExpectEOF awaits the end of the output, for example, once the program
+exits.
+*/
+func ExpectEOF() ExpectStep {
+	return ExpectStep{kind: stepEOF}
+}
+
+/*
+Within overrides the timeout of a step that awaits output.
+*/
+func (es ExpectStep) Within(timeout time.Duration) ExpectStep {
+	es.timeout = timeout
+	return es
+}
+
+/*
+String describes the step in failure messages.
+*/
+func (es ExpectStep) String() string {
+	switch es.kind {
+	case stepExpect:
+		return fmt.Sprintf("expect %s", es.match)
+	case stepSend:
+		return fmt.Sprintf("send %q", es.line)
+	case stepExact:
+		return fmt.Sprintf("expect %q", es.line)
+	case stepEnd:
+		return "end input"
+	}
+	return "expect EOF"
+}
+
+/*
+RunExpect executes the script 'steps' against 'rw', reading the program's
+output from it and writing the program's input to it, like the client end
+of NewDuplex or the master of a pseudo-terminal.  Steps awaiting output
+fail unless it's written within their timeout, which defaults to 'timeout'.
+
+It returns the transcript of the dialog: the output read interleaved with
+the input sent.  The error reports the first step that failed, along with
+an excerpt of the output it considered.  Output continues to be read from
+'rw' in the background until it returns an error, like io.EOF.
+*/
+func RunExpect(rw io.ReadWriter, timeout time.Duration, steps ...ExpectStep) (transcript string, err error) {
+	es := newExpectSession(rw)
+	go es.drain()
+	for i, step := range steps {
+		if step.timeout == 0 {
+			step.timeout = timeout
+		}
+		if err := es.run(step); err != nil {
+			return es.transcript(), fmt.Errorf("mckio: step %d %s failed: %w", i+1, step, err)
+		}
+	}
+	return es.transcript(), nil
+}
+
+/*
+ExpectStdio executes fn while os.Stdin and os.Stdout are replaced by pipes,
+conducting the dialog described by 'steps' with it, like RunExpect.  The end
+of output is reached once fn returns.  The original streams are restored
+before ExpectStdio returns, even when the script fails.  It isn't
+concurrency safe, as it replaces package-level variables.
+*/
+func ExpectStdio(fn func(), timeout time.Duration, steps ...ExpectStep) (transcript string, err error) {
+	inRdr, inWrt, err := os.Pipe()
+	if err != nil {
+		return "", err
+	}
+	defer inWrt.Close()
+	outRdr, captureEnd, err := FileCaptureReader(&os.Stdout)
+	if err != nil {
+		inRdr.Close()
+		return "", err
+	}
+	defer outRdr.Close()
+	origIn := os.Stdin
+	os.Stdin = inRdr
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer captureEnd()
+		fn()
+	}()
+	transcript, err = RunExpect(struct {
+		io.Reader
+		io.Writer
+	}{outRdr, inWrt}, timeout, steps...)
+	// closing stdin's write end releases a program awaiting more input.
+	inWrt.Close()
+	<-done
+	os.Stdin = origIn
+	inRdr.Close()
+	return transcript, err
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+const (
+	stepExpect = iota
+	stepSend
+	stepEOF
+	// the steps of RunRepl: exact output and the end of input.
+	stepExact
+	stepEnd
+)
+
+type expectSession struct {
+	mu      sync.Mutex
+	rw      io.ReadWriter
+	tscpt   []byte
+	pending []byte
+	readErr error
+	changed chan struct{}
+}
+
+func newExpectSession(rw io.ReadWriter) *expectSession {
+	return &expectSession{rw: rw, changed: make(chan struct{})}
+}
+
+// reads the output until the reader fails.
+func (es *expectSession) drain() {
+	p := make([]byte, 32*1024)
+	for {
+		n, err := es.rw.Read(p)
+		es.mu.Lock()
+		es.tscpt = append(es.tscpt, p[:n]...)
+		es.pending = append(es.pending, p[:n]...)
+		if err != nil {
+			es.readErr = err
+		}
+		// wake the step awaiting output, so it reevaluates.
+		close(es.changed)
+		es.changed = make(chan struct{})
+		es.mu.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+func (es *expectSession) run(step ExpectStep) error {
+	if step.kind == stepSend {
+		es.mu.Lock()
+		es.tscpt = append(es.tscpt, step.line+"\n"...)
+		es.mu.Unlock()
+		_, err := io.WriteString(es.rw, step.line+"\n")
+		return err
+	}
+	if step.kind == stepEnd {
+		if c, ok := es.rw.(io.Closer); ok {
+			return c.Close()
+		}
+		return nil
+	}
+	expire := time.NewTimer(step.timeout)
+	defer expire.Stop()
+	for {
+		es.mu.Lock()
+		if step.kind == stepExpect && step.match.MatchWrite(es.pending) {
+			es.pending = nil
+			es.mu.Unlock()
+			return nil
+		}
+		if step.kind == stepExact {
+			ok, err := exactPrefix(es.pending, step.line)
+			if ok {
+				es.pending = es.pending[len(step.line):]
+			}
+			if ok || err != nil {
+				es.mu.Unlock()
+				return err
+			}
+		}
+		readErr, pending, changed := es.readErr, string(es.pending), es.changed
+		es.mu.Unlock()
+		if step.kind == stepEOF && errors.Is(readErr, io.EOF) {
+			return nil
+		}
+		if readErr != nil {
+			return fmt.Errorf("output ended by %v - output \"%s\"", readErr, excerpt(pending))
+		}
+		select {
+		case <-changed:
+		case <-expire.C:
+			return fmt.Errorf("not satisfied within %v - output \"%s\": %w", step.timeout, excerpt(pending), os.ErrDeadlineExceeded)
+		}
+	}
+}
+func (es *expectSession) transcript() string {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	return string(es.tscpt)
+}
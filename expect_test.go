@@ -0,0 +1,61 @@
+package mckio
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// interactive program under test.
+func greeter() {
+	in := bufio.NewScanner(os.Stdin)
+	for fmt.Print("name? "); in.Scan(); fmt.Print("name? ") {
+		if in.Text() == "quit" {
+			fmt.Println("bye")
+			return
+		}
+		fmt.Printf("hello %s\n", in.Text())
+	}
+}
+func Test_ExpectStdio(t *testing.T) {
+	assrt := assert.New(t)
+	transcript, err := ExpectStdio(greeter, time.Second,
+		Expect(ExpectContains("name? ")),
+		Send("alice"),
+		Expect(ExpectRegexp(`hello alice\nname\? $`)),
+		Send("quit"),
+		Expect(ExpectContains("bye")),
+		ExpectEOF(),
+	)
+	assrt.Nil(err)
+	assrt.Equal("name? alice\nhello alice\nname? quit\nbye\n", transcript)
+}
+func Test_ExpectStdioFailure(t *testing.T) {
+	assrt := assert.New(t)
+	origIn, origOut := os.Stdin, os.Stdout
+	_, err := ExpectStdio(greeter, time.Second,
+		Expect(ExpectContains("name? ")),
+		Expect(ExpectContains("password: ")).Within(10*time.Millisecond),
+	)
+	assrt.True(errors.Is(err, os.ErrDeadlineExceeded))
+	assrt.True(strings.HasPrefix(err.Error(), `mckio: step 2 expect containing "password: " failed`))
+	assrt.Equal(origIn, os.Stdin)
+	assrt.Equal(origOut, os.Stdout)
+}
+func Test_RunExpectEcho(t *testing.T) {
+	assrt := assert.New(t)
+	client := NewEchoPeer(nil, 0, nil)
+	defer client.Close()
+	transcript, err := RunExpect(client, time.Second,
+		Send("ping"),
+		Expect(ExpectExact("ping\n")),
+	)
+	assrt.Nil(err)
+	assrt.Equal("ping\nping\n", transcript)
+}
@@ -0,0 +1,174 @@
+package mckio
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+/*
+Fairness measures how a consumer divides its attention among several mock
+readers.  Each reader wrapped by Fairness records the bytes and reads it
+delivers, accumulated into time buckets of a fixed duration.
+
+- Report returns the consumption per source per time bucket.
+
+- Fair detects a source that remained unread, although it wasn't exhausted,
+while the consumer continued reading other sources.
+
+The following behavior of Fairness can be configured:
+
+- BehaviorClocker (optional) - supplies the Clock assigning reads to
+buckets.  When undefined - uses the system clock.
+
+- Fairness is concurrency safe.
+*/
+type Fairness struct {
+	mu      sync.Mutex
+	bucket  time.Duration
+	start   time.Time
+	clock   Clock
+	sources []fairSource
+}
+
+/*
+FairnessBucket reports, per source, the bytes and number of reads
+delivered during a time bucket.  Bytes and Reads are indexed in the order
+sources were wrapped.
+*/
+type FairnessBucket struct {
+	Start time.Duration
+	Bytes []int64
+	Reads []int
+}
+
+/*
+NewFairness creates a Fairness accumulating reads into buckets spanning
+the 'bucket' duration, whose clock can be configured using BehaviorClocker.
+The first bucket starts when NewFairness is called.  Panics if 'bucket'
+isn't positive.
+*/
+func NewFairness(bucket time.Duration, behavior interface{}) *Fairness {
+	if bucket <= 0 {
+		panic("mckio: Fairness bucket must be positive")
+	}
+	clock := clockOf(behavior)
+	return &Fairness{bucket: bucket, start: clock.Now(), clock: clock}
+}
+
+/*
+Wrap returns an io.Reader that records reads of 'r' under the source 'name'.
+*/
+func (f *Fairness) Wrap(name string, r io.Reader) io.Reader {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sources = append(f.sources, fairSource{name: name, eofBucket: -1})
+	return fairReader{f: f, src: len(f.sources) - 1, r: r}
+}
+
+/*
+Report returns the consumption of each source per time bucket, from the
+first bucket through the one containing the latest read.
+*/
+func (f *Fairness) Report() []FairnessBucket {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var last int
+	for _, src := range f.sources {
+		for _, rd := range src.reads {
+			if rd.bucket > last {
+				last = rd.bucket
+			}
+		}
+	}
+	rpt := make([]FairnessBucket, last+1)
+	for b := range rpt {
+		rpt[b] = FairnessBucket{
+			Start: time.Duration(b) * f.bucket,
+			Bytes: make([]int64, len(f.sources)),
+			Reads: make([]int, len(f.sources)),
+		}
+	}
+	for s, src := range f.sources {
+		for _, rd := range src.reads {
+			rpt[rd.bucket].Bytes[s] += int64(rd.n)
+			rpt[rd.bucket].Reads[s]++
+		}
+	}
+	return rpt
+}
+
+/*
+Fair returns an error identifying the first source that wasn't read for
+more than 'maxIdle' consecutive buckets while the consumer read other
+sources.  Buckets after a source returned io.EOF aren't considered.
+*/
+func (f *Fairness) Fair(maxIdle int) error {
+	rpt := f.Report()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for s, src := range f.sources {
+		var idle int
+		for b, bkt := range rpt {
+			if src.eofBucket > -1 && b > src.eofBucket {
+				break
+			}
+			if bkt.Reads[s] > 0 {
+				idle = 0
+				continue
+			}
+			if othersRead(bkt, s) {
+				idle++
+			}
+			if idle > maxIdle {
+				return fmt.Errorf("mckio: source %q starved for %d buckets of %v while other sources were read", src.name, idle, f.bucket)
+			}
+		}
+	}
+	return nil
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+type fairSource struct {
+	name      string
+	reads     []fairRead
+	eofBucket int
+}
+type fairRead struct {
+	bucket int
+	n      int
+}
+type fairReader struct {
+	f   *Fairness
+	src int
+	r   io.Reader
+}
+
+func (fr fairReader) Read(p []byte) (int, error) {
+	n, err := fr.r.Read(p)
+	fr.f.record(fr.src, n, err)
+	return n, err
+}
+func (f *Fairness) record(src int, n int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	bucket := int(f.clock.Now().Sub(f.start) / f.bucket)
+	if n > 0 {
+		f.sources[src].reads = append(f.sources[src].reads, fairRead{bucket: bucket, n: n})
+	}
+	if err == io.EOF && f.sources[src].eofBucket < 0 {
+		f.sources[src].eofBucket = bucket
+	}
+}
+func othersRead(bkt FairnessBucket, src int) bool {
+	for s, rds := range bkt.Reads {
+		if s != src && rds > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package mckio
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FairnessRoundRobin(t *testing.T) {
+	assrt := assert.New(t)
+	fair := NewFairness(time.Millisecond, nil)
+	a := NewNonBlockNoDelim([]string{"a1", "a2", "a3"})
+	b := NewNonBlockNoDelim([]string{"b1", "b2", "b3"})
+	srcs := []io.Reader{fair.Wrap("a", &a), fair.Wrap("b", &b)}
+	p := make([]byte, 2)
+	for i := 0; i < 3; i++ {
+		for _, src := range srcs {
+			src.Read(p)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	// tolerate a bucket boundary falling between reads of a and b
+	assrt.Nil(fair.Fair(1))
+	var bytes [2]int64
+	for _, bkt := range fair.Report() {
+		bytes[0] += bkt.Bytes[0]
+		bytes[1] += bkt.Bytes[1]
+	}
+	assrt.Equal([2]int64{6, 6}, bytes)
+}
+func Test_FairnessStarved(t *testing.T) {
+	assrt := assert.New(t)
+	fair := NewFairness(time.Millisecond, nil)
+	a := NewNonBlockNoDelim([]string{"a1", "a2", "a3", "a4"})
+	b := NewNonBlockNoDelim([]string{"b1"})
+	fa, _ := fair.Wrap("a", &a), fair.Wrap("b", &b)
+	p := make([]byte, 2)
+	// consumer only reads from 'a'
+	for i := 0; i < 4; i++ {
+		fa.Read(p)
+		time.Sleep(2 * time.Millisecond)
+	}
+	assrt.NotNil(fair.Fair(2))
+}
+func Test_FairnessClock(t *testing.T) {
+	assrt := assert.New(t)
+	fc := NewFakeClock(time.Time{})
+	fair := NewFairness(time.Second, fc)
+	a := NewNonBlockNoDelim([]string{"a1", "a2"})
+	fa := fair.Wrap("a", &a)
+	p := make([]byte, 2)
+	fa.Read(p)
+	fc.Advance(2 * time.Second)
+	fa.Read(p)
+	rpt := fair.Report()
+	if assrt.Len(rpt, 3) {
+		assrt.Equal([]int64{2}, rpt[0].Bytes)
+		assrt.Equal([]int64{0}, rpt[1].Bytes)
+		assrt.Equal(2*time.Second, rpt[2].Start)
+		assrt.Equal([]int64{2}, rpt[2].Bytes)
+	}
+}
+func Test_FairnessBucketPanics(t *testing.T) {
+	assrt := assert.New(t)
+	assrt.Panics(func() { NewFairness(0, nil) })
+}
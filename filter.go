@@ -0,0 +1,140 @@
+package mckio
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+/*
+Filter emulates a co-process filter, like grep or tr, without executing one.
+Bytes written to the Filter are transformed by a supplied function and become
+readable from the same Filter, permitting code that pipes data through an
+external filter to be tested in memory.
+
+The following behavior of Filter can be configured:
+
+- BehaviorBufferLimiter (optional) - specifies the maximum number of
+transformed bytes buffered between the writer and reader.  A write that
+would exceed this limit blocks until the reader drains enough of the buffer.
+When undefined - the buffer is unbounded.
+
+- BehaviorMemoryGuarder (optional) - limits the number of bytes buffered,
+notifying the guard when a write would exceed it.  Writes exceeding the
+limit return ErrMemoryGuard.  Unlike BehaviorBufferLimiter, it detects,
+instead of blocks, a writer outpacing the reader.  When undefined - no
+guard is applied.
+
+- BehaviorBlockBeforeEachReader (optional) - specifies an implementation
+blocking the reader before it attempts to read, pacing the filter's output.
+When undefined - the read immediately executes.
+
+- Read blocks until transformed bytes are available.  Once Close is called
+and the buffer drained, Read returns io.EOF.
+
+- Filter is concurrency safe: typically one goroutine writes while another
+reads.
+*/
+type Filter struct {
+	mu          sync.Mutex
+	avail       *sync.Cond
+	buf         bytes.Buffer
+	limit       int
+	guard       memGuard
+	closed      bool
+	transform   func(p []byte) []byte
+	blockBefore func()
+}
+
+/*
+BehaviorBufferLimiter specifies the maximum number of bytes a mock buffers
+internally.
+*/
+type BehaviorBufferLimiter interface {
+	BehaviorBufferLimit() int
+}
+
+/*
+NewFilter creates a Filter applying transform to the content of every write.
+The transform function receives a copy of the written bytes and returns the
+bytes made available to the reader.  It may return nil to suppress output,
+like grep dropping a nonmatching line.  A nil transform copies the written
+bytes unchanged.
+*/
+func NewFilter(transform func(p []byte) []byte, behavior interface{}) *Filter {
+	f := &Filter{transform: transform}
+	f.avail = sync.NewCond(&f.mu)
+	if f.transform == nil {
+		f.transform = func(p []byte) []byte { return p }
+	}
+	if bl, ok := behavior.(BehaviorBufferLimiter); ok {
+		f.limit = bl.BehaviorBufferLimit()
+	}
+	f.guard = newMemGuard(behavior)
+	f.blockBefore = func() {}
+	if bkb, ok := behavior.(BehaviorBlockBeforeEachReader); ok {
+		f.blockBefore = func() {
+			bkb.BehaviorBlockBeforeEachRead()
+		}
+	}
+	return f
+}
+
+/*
+Write transforms p and buffers the result for the reader.  It blocks while
+the buffer limit would be exceeded.  However, when the buffer is empty, the
+transformed bytes are accepted regardless of their length to avoid
+deadlocking the writer.  Writing after Close returns io.ErrClosedPipe.
+*/
+func (f *Filter) Write(p []byte) (int, error) {
+	out := f.transform(append([]byte(nil), p...))
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for !f.closed && f.limit > 0 && f.buf.Len() > 0 && f.buf.Len()+len(out) > f.limit {
+		f.avail.Wait()
+	}
+	if f.closed {
+		return 0, io.ErrClosedPipe
+	}
+	if f.guard.exceeds(f.buf.Len() + len(out)) {
+		return 0, ErrMemoryGuard
+	}
+	f.buf.Write(out)
+	f.avail.Broadcast()
+	return len(p), nil
+}
+
+/*
+Read implements an io.Reader over the transformed bytes conforming to
+io.Reader semantics (https://golang.org/pkg/io/#Reader).
+*/
+func (f *Filter) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	f.blockBefore()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for !f.closed && f.buf.Len() == 0 {
+		f.avail.Wait()
+	}
+	if f.buf.Len() == 0 {
+		return 0, io.EOF
+	}
+	n, _ := f.buf.Read(p)
+	// wake writers waiting for buffer space
+	f.avail.Broadcast()
+	return n, nil
+}
+
+/*
+Close signals the end of input, like closing a co-process's stdin.  The
+reader receives io.EOF after draining the remaining transformed bytes.
+*/
+func (f *Filter) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	f.avail.Broadcast()
+	return nil
+}
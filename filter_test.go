@@ -0,0 +1,66 @@
+package mckio
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FilterTransform(t *testing.T) {
+	assrt := assert.New(t)
+	grep := func(p []byte) []byte {
+		if bytes.Contains(p, []byte("match")) {
+			return bytes.ToUpper(p)
+		}
+		return nil
+	}
+	f := NewFilter(grep, nil)
+	go func() {
+		defer f.Close()
+		for _, ln := range []string{"match 1\n", "skip\n", "match 2\n"} {
+			f.Write([]byte(ln))
+		}
+	}()
+	out, err := io.ReadAll(f)
+	assrt.Nil(err)
+	assrt.Equal("MATCH 1\nMATCH 2\n", string(out))
+}
+func Test_FilterBufferLimit(t *testing.T) {
+	assrt := assert.New(t)
+	f := NewFilter(nil, filterLimit{})
+	sz, err := f.Write([]byte("0123"))
+	assrt.Equal(4, sz)
+	assrt.Nil(err)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f.Write([]byte("4567"))
+	}()
+	select {
+	case <-done:
+		assrt.Fail("write should block when exceeding buffer limit")
+	case <-time.After(10 * time.Millisecond):
+	}
+	p := make([]byte, 4)
+	sz, err = f.Read(p)
+	assrt.Equal(4, sz)
+	assrt.Nil(err)
+	<-done
+	f.Close()
+	sz, err = f.Read(p)
+	assrt.Equal("4567", string(p[:sz]))
+	sz, err = f.Read(p)
+	assrt.Zero(sz)
+	assrt.IsType(io.EOF, err)
+	_, err = f.Write([]byte("late"))
+	assrt.Equal(io.ErrClosedPipe, err)
+}
+
+type filterLimit struct{}
+
+func (filterLimit) BehaviorBufferLimit() int {
+	return 5
+}
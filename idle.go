@@ -0,0 +1,65 @@
+package mckio
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+/*
+BehaviorIdleTimeouter specifies the duration a reader waits, once its
+scripted input has been exhausted, before reporting a timeout.
+*/
+type BehaviorIdleTimeouter interface {
+	BehaviorIdleTimeout() time.Duration
+}
+
+/*
+IdleTimeout implements BehaviorIdleTimeouter returning its duration.  Embed
+it in a behavior struct to combine it with other behaviors.
+*/
+type IdleTimeout time.Duration
+
+/*
+BehaviorIdleTimeout returns the duration.
+*/
+func (it IdleTimeout) BehaviorIdleTimeout() time.Duration {
+	return time.Duration(it)
+}
+
+/*
+NewConsoleIdle simulates an io.Reader on os.Stdin like NewConsole, however,
+once the provided strings have been exhausted, each Read waits 'idle' then
+returns an error wrapping os.ErrDeadlineExceeded, simulating a user who
+stopped typing, so idle-timeout prompts can be tested.
+
+The following behavior of NewConsoleIdle can be configured:
+
+- BehaviorClocker (optional) - supplies the Clock measuring the delay
+before each read and the idle wait.  When undefined - uses the system clock.
+*/
+func NewConsoleIdle(cmdLns []string, idle time.Duration, behavior interface{}) (rdr Rstrings) {
+	return NewRstrings(cmdLns, stdinIdle{
+		stdin:       stdin{clock: clockOf(behavior)},
+		IdleTimeout: IdleTimeout(idle),
+	})
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+type stdinIdle struct {
+	stdin
+	IdleTimeout
+}
+
+func (s stdinIdle) BehaviorClock() Clock {
+	return s.clock
+}
+
+// waits for the idle duration then reports the timeout.
+func (m *Rstrings) idleTimeout() error {
+	blocking(&m.blocked, func() { m.clock.Sleep(m.idle) })
+	return fmt.Errorf("mckio: input idle for %v: %w", m.idle, os.ErrDeadlineExceeded)
+}
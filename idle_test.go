@@ -0,0 +1,66 @@
+package mckio
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type idleClock struct {
+	IdleTimeout
+	*FakeClock
+}
+
+func Test_RstringsIdleTimeout(t *testing.T) {
+	assrt := assert.New(t)
+	fc := NewFakeClock(time.Time{})
+	rdr := NewRstrings([]string{"a"}, idleClock{IdleTimeout(time.Minute), fc})
+	fc.SetAutoAdvance(&rdr)
+	p := make([]byte, 10)
+	n, err := rdr.Read(p)
+	assrt.Nil(err)
+	assrt.Equal("a", string(p[:n]))
+	n, err = rdr.Read(p)
+	assrt.Equal(0, n)
+	assrt.True(errors.Is(err, os.ErrDeadlineExceeded))
+	assrt.Equal(time.Time{}.Add(time.Minute), fc.Now())
+	// the user remains idle.
+	_, err = rdr.Read(p)
+	assrt.True(errors.Is(err, os.ErrDeadlineExceeded))
+	assrt.Equal(time.Time{}.Add(2*time.Minute), fc.Now())
+}
+func Test_RstringsIdleTimeoutSupersedesBlock(t *testing.T) {
+	assrt := assert.New(t)
+	rdr := NewRstrings(nil, struct {
+		IdleTimeout
+		blockForever
+	}{IdleTimeout: IdleTimeout(time.Millisecond)})
+	_, err := rdr.Read(make([]byte, 1))
+	assrt.True(errors.Is(err, os.ErrDeadlineExceeded))
+	assrt.NotEqual(io.EOF, err)
+}
+func Test_ConsoleIdle(t *testing.T) {
+	assrt := assert.New(t)
+	fc := NewFakeClock(time.Time{})
+	rdr := NewConsoleIdle([]string{"y"}, 30*time.Second, fc)
+	fc.SetAutoAdvance(&rdr)
+	p := make([]byte, 10)
+	n, err := rdr.Read(p)
+	assrt.Nil(err)
+	assrt.Equal("y\n", string(p[:n]))
+	_, err = rdr.Read(p)
+	assrt.True(errors.Is(err, os.ErrDeadlineExceeded))
+	assrt.Contains(err.Error(), "idle for 30s")
+	// the console's delay precedes the idle wait.
+	assrt.Equal(time.Time{}.Add(32*time.Second), fc.Now())
+}
+
+type blockForever struct{}
+
+func (blockForever) BehaviorBlockAtEnd() {
+	select {}
+}
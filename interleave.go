@@ -0,0 +1,146 @@
+package mckio
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+/*
+Interleave accounts for the alternation between consuming input and producing
+output of REPL-style programs.  It wraps the reader supplying stdin and the
+writer receiving stdout, recording each Read and Write that transfers at
+least one byte, in the order they occurred.
+
+- Pattern summarizes the recorded order, for example "RWRW".
+
+- Alternates verifies every read of input is followed by output before the
+next read.  This detects programs that read the next command before
+finishing the previous command's output.
+
+- Interleave is concurrency safe.
+*/
+type Interleave struct {
+	mu     sync.Mutex
+	events []IOEvent
+	in     io.Reader
+	out    io.Writer
+}
+
+/*
+IOEvent records a single Read or Write that transferred data.  Op is 'R'
+for a read and 'W' for a write.
+*/
+type IOEvent struct {
+	Op   byte
+	Data string
+}
+
+/*
+NewInterleave wraps the reader 'in' and writer 'out' so their use is
+recorded.  Provide the values returned by Reader and Writer to the code
+under test.
+*/
+func NewInterleave(in io.Reader, out io.Writer) *Interleave {
+	return &Interleave{in: in, out: out}
+}
+
+/*
+Reader returns an io.Reader that records reads from the wrapped reader.
+*/
+func (il *Interleave) Reader() io.Reader {
+	return interleaveReader{il}
+}
+
+/*
+Writer returns an io.Writer that records writes to the wrapped writer.
+*/
+func (il *Interleave) Writer() io.Writer {
+	return interleaveWriter{il}
+}
+
+/*
+Events returns a copy of the recorded reads and writes.
+*/
+func (il *Interleave) Events() []IOEvent {
+	il.mu.Lock()
+	defer il.mu.Unlock()
+	return append([]IOEvent(nil), il.events...)
+}
+
+/*
+Pattern returns the order of recorded operations where consecutive
+operations of the same kind are collapsed into one.  For example, two
+reads followed by three writes results in "RW".
+*/
+func (il *Interleave) Pattern() string {
+	il.mu.Lock()
+	defer il.mu.Unlock()
+	var pat strings.Builder
+	var last byte
+	for _, ev := range il.events {
+		if ev.Op != last {
+			pat.WriteByte(ev.Op)
+			last = ev.Op
+		}
+	}
+	return pat.String()
+}
+
+/*
+Alternates returns an error identifying the first input read before the
+output of the preceding command was written.  Commands are assumed to be
+newline terminated, as a command may be delivered over several reads.  A
+single read returning several commands isn't a violation, as the wrapped
+reader decides how much a read returns, however, reading again before
+writing the output of a command already read is.
+*/
+func (il *Interleave) Alternates() error {
+	il.mu.Lock()
+	defer il.mu.Unlock()
+	var pending string
+	for _, ev := range il.events {
+		if ev.Op == 'W' {
+			pending = ""
+			continue
+		}
+		if i := strings.IndexByte(pending, '\n'); i > -1 {
+			return fmt.Errorf("mckio: read \"%s\" before writing output for command \"%s\"", excerpt(ev.Data), excerpt(pending[:i+1]))
+		}
+		pending += ev.Data
+	}
+	return nil
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+type interleaveReader struct {
+	il *Interleave
+}
+
+func (ir interleaveReader) Read(p []byte) (int, error) {
+	n, err := ir.il.in.Read(p)
+	ir.il.record('R', p[:n])
+	return n, err
+}
+
+type interleaveWriter struct {
+	il *Interleave
+}
+
+func (iw interleaveWriter) Write(p []byte) (int, error) {
+	n, err := iw.il.out.Write(p)
+	iw.il.record('W', p[:n])
+	return n, err
+}
+func (il *Interleave) record(op byte, p []byte) {
+	if len(p) < 1 {
+		return
+	}
+	il.mu.Lock()
+	defer il.mu.Unlock()
+	il.events = append(il.events, IOEvent{Op: op, Data: string(p)})
+}
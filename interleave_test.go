@@ -0,0 +1,69 @@
+package mckio
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_InterleaveAlternates(t *testing.T) {
+	assrt := assert.New(t)
+	in := NewRstrings([]string{"cmmd 1", "cmmd 2"}, delimAdd{})
+	var out bytes.Buffer
+	il := NewInterleave(&in, &out)
+	replLineAtATime(il.Reader(), il.Writer())
+	assrt.Equal("RWRW", il.Pattern())
+	assrt.Nil(il.Alternates())
+	assrt.Equal("ran cmmd 1\nran cmmd 2\n", out.String())
+}
+func Test_InterleaveReadAhead(t *testing.T) {
+	assrt := assert.New(t)
+	in := NewRstrings([]string{"cmmd 1", "cmmd 2"}, delimAdd{})
+	var out bytes.Buffer
+	il := NewInterleave(&in, &out)
+	// reads the second command before writing the first one's output.
+	p := make([]byte, 7)
+	for i := 0; i < 2; i++ {
+		il.Reader().Read(p)
+	}
+	fmt.Fprint(il.Writer(), "ran cmmd 1\nran cmmd 2\n")
+	assrt.Equal("RW", il.Pattern())
+	err := il.Alternates()
+	assrt.NotNil(err)
+	assrt.Contains(err.Error(), "read \"cmmd 2\n\" before writing output for command \"cmmd 1\n\"")
+	assrt.Len(il.Events(), 3)
+}
+func Test_InterleaveAlternatesLinesPerRead(t *testing.T) {
+	assrt := assert.New(t)
+	// a single read delivers both commands
+	in := strings.NewReader("cmmd 1\ncmmd 2\n")
+	var out bytes.Buffer
+	il := NewInterleave(in, &out)
+	scn := bufio.NewScanner(il.Reader())
+	for scn.Scan() {
+		fmt.Fprintf(il.Writer(), "ran %s\n", scn.Text())
+	}
+	assrt.Equal("RW", il.Pattern())
+	assrt.Nil(il.Alternates())
+}
+func replLineAtATime(in io.Reader, out io.Writer) {
+	// reads a byte at a time so it never consumes beyond the current line.
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		if _, err := in.Read(b); err != nil {
+			return
+		}
+		if b[0] != '\n' {
+			line = append(line, b[0])
+			continue
+		}
+		fmt.Fprintf(out, "ran %s\n", line)
+		line = line[:0]
+	}
+}
@@ -0,0 +1,64 @@
+package mckio
+
+import (
+	"os"
+	"time"
+)
+
+/*
+Interrupt implements BehaviorAfterElementer by interrupting the program
+once the string at index Element has been consumed, so signal handling
+tied to console interaction can be tested deterministically.  Embed it in a
+behavior struct to combine it with other behaviors.
+
+- Delay - postpones the interrupt, which is then delivered asynchronously.
+When zero - the interrupt is delivered before the reader returns the bytes
+following Element.
+
+- Clock - supplies the Clock measuring Delay, like a FakeClock, as
+Interrupt can't access the behavior embedding it.  When nil - Delay is
+measured by the wall clock.
+
+- Fn - invoked to interrupt the program.  When nil - os.Interrupt is sent
+to the current process, which requires signal.Notify to have been called
+for os.Interrupt, otherwise the process terminates.  Sending os.Interrupt
+is unsupported on Windows, so supply Fn there.
+*/
+type Interrupt struct {
+	Element int
+	Delay   time.Duration
+	Clock   BehaviorClocker
+	Fn      func()
+}
+
+/*
+BehaviorAfterElement interrupts the program once 'index' reaches Element.
+*/
+func (in Interrupt) BehaviorAfterElement(index int) {
+	if index != in.Element {
+		return
+	}
+	fire := in.Fn
+	if fire == nil {
+		fire = interruptSelf
+	}
+	if in.Delay > 0 {
+		timer := clockOf(in.Clock).NewTimer(in.Delay)
+		go func() {
+			<-timer.C()
+			fire()
+		}()
+		return
+	}
+	fire()
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+func interruptSelf() {
+	if proc, err := os.FindProcess(os.Getpid()); err == nil {
+		proc.Signal(os.Interrupt)
+	}
+}
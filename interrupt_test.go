@@ -0,0 +1,53 @@
+package mckio
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_InterruptFn(t *testing.T) {
+	assrt := assert.New(t)
+	var consumed []int
+	rdr := NewRstrings([]string{"a", "b", "c"}, Interrupt{Element: 1, Fn: func() {
+		consumed = append(consumed, 1)
+	}})
+	p := make([]byte, 1)
+	rdr.Read(p)
+	rdr.Read(p)
+	assrt.Empty(consumed)
+	rdr.Read(p)
+	assrt.Equal([]int{1}, consumed)
+	io.ReadAll(&rdr)
+	assrt.Equal([]int{1}, consumed)
+}
+func Test_InterruptDelay(t *testing.T) {
+	assrt := assert.New(t)
+	fired := make(chan struct{})
+	rdr := NewRstrings([]string{"a"}, Interrupt{Delay: 10 * time.Millisecond, Fn: func() {
+		close(fired)
+	}})
+	start := time.Now()
+	io.ReadAll(&rdr)
+	<-fired
+	assrt.True(time.Since(start) >= 10*time.Millisecond)
+}
+func Test_InterruptDelayClock(t *testing.T) {
+	assrt := assert.New(t)
+	fc := NewFakeClock(time.Time{})
+	fired := make(chan struct{})
+	rdr := NewRstrings([]string{"a"}, Interrupt{Delay: time.Hour, Clock: fc, Fn: func() {
+		close(fired)
+	}})
+	io.ReadAll(&rdr)
+	fakeClockWait(fc, 1)
+	select {
+	case <-fired:
+		assrt.Fail("interrupted before the delay elapsed")
+	default:
+	}
+	fc.Advance(time.Hour)
+	<-fired
+}
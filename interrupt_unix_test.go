@@ -0,0 +1,37 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package mckio
+
+import (
+	"os"
+	"os/signal"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_InterruptSignal(t *testing.T) {
+	assrt := assert.New(t)
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	defer signal.Stop(sig)
+	rdr := NewRstrings([]string{"first", "second"}, struct {
+		delimAdd
+		Interrupt
+	}{Interrupt: Interrupt{Element: 0}})
+	p := make([]byte, 6)
+	rdr.Read(p)
+	select {
+	case <-sig:
+		t.Fatal("interrupted before consuming the element")
+	case <-time.After(10 * time.Millisecond):
+	}
+	rdr.Read(p)
+	select {
+	case s := <-sig:
+		assrt.Equal(os.Interrupt, s)
+	case <-time.After(time.Second):
+		t.Fatal("interrupt not delivered")
+	}
+}
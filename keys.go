@@ -0,0 +1,72 @@
+package mckio
+
+/*
+Key sequences typed by common keys of a VT100/xterm compatible terminal in
+its normal cursor mode.  Concatenate them to script console input, for
+example, NewKeystrokes("ls"+KeyArrowLeft+KeyBackspace+KeyEnter, nil),
+exercising line editing and history without memorizing escape codes.
+*/
+const (
+	KeyArrowUp    = "\x1b[A"
+	KeyArrowDown  = "\x1b[B"
+	KeyArrowRight = "\x1b[C"
+	KeyArrowLeft  = "\x1b[D"
+	KeyHome       = "\x1b[H"
+	KeyEnd        = "\x1b[F"
+	KeyInsert     = "\x1b[2~"
+	KeyDelete     = "\x1b[3~"
+	KeyPageUp     = "\x1b[5~"
+	KeyPageDown   = "\x1b[6~"
+	KeyF1         = "\x1bOP"
+	KeyF2         = "\x1bOQ"
+	KeyF3         = "\x1bOR"
+	KeyF4         = "\x1bOS"
+	KeyEscape     = "\x1b"
+	KeyTab        = "\t"
+	KeyEnter      = "\r"
+	KeyBackspace  = "\x7f"
+)
+
+/*
+Control characters typed by holding Ctrl with a letter.
+*/
+const (
+	KeyCtrlA = "\x01"
+	KeyCtrlB = "\x02"
+	KeyCtrlC = "\x03"
+	KeyCtrlD = "\x04"
+	KeyCtrlE = "\x05"
+	KeyCtrlF = "\x06"
+	KeyCtrlH = "\x08"
+	KeyCtrlK = "\x0b"
+	KeyCtrlL = "\x0c"
+	KeyCtrlN = "\x0e"
+	KeyCtrlP = "\x10"
+	KeyCtrlR = "\x12"
+	KeyCtrlU = "\x15"
+	KeyCtrlW = "\x17"
+	KeyCtrlZ = "\x1a"
+)
+
+/*
+Ctrl returns the control character typed by holding Ctrl with 'key', a
+letter of either case or one of @[\]^_, like Ctrl('c') for KeyCtrlC.  Other
+keys are returned unchanged.
+*/
+func Ctrl(key byte) string {
+	switch {
+	case key >= 'a' && key <= 'z':
+		return string(rune(key - 'a' + 1))
+	case key >= '@' && key <= '_':
+		return string(rune(key - '@'))
+	}
+	return string(rune(key))
+}
+
+/*
+Alt returns the sequence typed by holding Alt, or Meta, with 'key': the key
+prefixed by KeyEscape.
+*/
+func Alt(key string) string {
+	return KeyEscape + key
+}
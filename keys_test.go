@@ -0,0 +1,32 @@
+package mckio
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_KeysTyped(t *testing.T) {
+	assrt := assert.New(t)
+	rdr := NewKeystrokes("ls"+KeyArrowLeft+KeyDelete+Alt("b")+KeyCtrlC+KeyEnter, nil)
+	var keys []string
+	p := make([]byte, 8)
+	for {
+		n, err := rdr.Read(p)
+		if err == io.EOF {
+			break
+		}
+		keys = append(keys, string(p[:n]))
+	}
+	assrt.Equal([]string{"l", "s", KeyArrowLeft, KeyDelete, "\x1bb", KeyCtrlC, KeyEnter}, keys)
+}
+func Test_KeysCtrl(t *testing.T) {
+	assrt := assert.New(t)
+	assrt.Equal(KeyCtrlC, Ctrl('c'))
+	assrt.Equal(KeyCtrlC, Ctrl('C'))
+	assrt.Equal(KeyCtrlZ, Ctrl('z'))
+	assrt.Equal(KeyEscape, Ctrl('['))
+	assrt.Equal("\x00", Ctrl('@'))
+	assrt.Equal("1", Ctrl('1'))
+}
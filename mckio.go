@@ -5,11 +5,15 @@ package mckio
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
-
-	"github.com/WhisperingChaos/bus"
 )
 
 /*
@@ -22,6 +26,10 @@ The following behavior of Rstrings can be configured:
 concatenated to the end of each string comprising the list of strings.
 When undefined - no concatenation occurs.
 
+- BehaviorDelimFuncer (optional) - computes the delimiter concatenated
+to the end of each individual string.  When defined - it supersedes
+BehaviorDelimer.
+
 - BehaviorBlockAtEnder (optional) - specifies an implementation that
 blocks the reader after the list of strings has been exhausted instead
 of signaling io.EOF.  When undefined - signals io.EOF.
@@ -30,6 +38,25 @@ of signaling io.EOF.  When undefined - signals io.EOF.
 blocking the reader before it attempts to read the first/next string.
 When undefined - the read immediately executes.
 
+- BehaviorEchoer (optional) - specifies a writer receiving a copy of the
+bytes returned by each Read, like a terminal in cooked mode echoing typed
+input, so a transcript written to the same writer as the program's output
+matches what a user would see.  When undefined - no echo occurs.
+
+- BehaviorAfterElementer (optional) - notified once each string, including
+its delimiter, has been consumed, when the next Read begins, so events,
+like an interrupt, can be coordinated with the program's consumption of
+input.  When undefined - no notification occurs.
+
+- BehaviorIdleTimeouter (optional) - specifies the duration Read waits
+after the list of strings has been exhausted before returning an error
+wrapping os.ErrDeadlineExceeded, simulating a user who stopped typing.
+When defined - it supersedes BehaviorBlockAtEnder.  When undefined -
+executes BehaviorBlockAtEnder.
+
+- BehaviorClocker (optional) - supplies the Clock measuring the wait
+specified by BehaviorIdleTimeouter.  When undefined - uses the system clock.
+
 Notes
 
 - Although golang defines a string as "just a bunch of bytes" use caution
@@ -44,9 +71,15 @@ type Rstrings struct {
 	ccur        int
 	dcur        int
 	list        []string
-	delim       []byte
+	delims      [][]byte
 	blockBefore func()
 	block       func()
+	blocked     int32
+	afterElem   func(index int)
+	notified    int
+	echo        io.Writer
+	idle        time.Duration
+	clock       Clock
 }
 
 /*
@@ -57,6 +90,17 @@ type BehaviorDelimer interface {
 	BehaviorDelim() []byte
 }
 
+/*
+BehaviorDelimFuncer computes the delimiter concatenated to a specific element
+of a list of strings.  It receives the element's index and value, enabling
+delimiters that vary per element, like length-prefixed framing or
+alternating record separators within a single reader.  Returning nil or an
+empty slice omits the delimiter for that element.
+*/
+type BehaviorDelimFuncer interface {
+	BehaviorDelimFunc(index int, s string) []byte
+}
+
 /*
 BehaviorBlockAtEnder provides a blocking mechanism that's executed once
 the reader has been exhausted.  A select{} statement offers a simple
@@ -74,6 +118,27 @@ type BehaviorBlockBeforeEachReader interface {
 	BehaviorBlockBeforeEachRead()
 }
 
+/*
+BehaviorEchoer specifies the writer echoing the input delivered by a reader.
+*/
+type BehaviorEchoer interface {
+	BehaviorEcho() io.Writer
+}
+
+/*
+BehaviorAfterElementer receives the index of each string consumed by a
+reader, before the reader delivers the bytes that follow it.
+*/
+type BehaviorAfterElementer interface {
+	BehaviorAfterElement(index int)
+}
+
+/*
+ScriptEOF is an element of the list supplied to NewRstrings that injects
+end of file between its neighbouring elements.
+*/
+const ScriptEOF = "\x00mckio:EOF\x00"
+
 /*
 NewRstrings implements an io.Reader interface over a list of strings.  Its
 behavior can be configured to:
@@ -81,16 +146,24 @@ behavior can be configured to:
 - optionally block at the start of each read call,
 
 - optionally concatenate a delimiter sequence at the end of each string element,
+either a fixed one or one computed for each element,
 
 - optionally block after the entire list of strings has been exhausted.
 
 Independently specify these behaviors using BehaviorBlockBeforeEachReader,
-BehaviorDelimer, and BehaviorBlockAtEnder.
+BehaviorDelimer or BehaviorDelimFuncer, and BehaviorBlockAtEnder.
+
+An element equal to ScriptEOF simulates Ctrl-D typed on a terminal: Read
+returns io.EOF once upon reaching it, then resumes delivering the elements
+that follow.  It contributes no bytes and no delimiter.
 */
 func NewRstrings(list []string, behavior interface{}) (rdr Rstrings) {
 	rdr.list = list
 	if pd, ok := behavior.(BehaviorDelimer); ok {
-		rdr.delim = pd.BehaviorDelim()
+		rdr.delims = delimsFixed(list, pd.BehaviorDelim())
+	}
+	if pdf, ok := behavior.(BehaviorDelimFuncer); ok {
+		rdr.delims = delimsCompute(list, pdf.BehaviorDelimFunc)
 	}
 	rdr.block = func() {}
 	if bk, ok := behavior.(BehaviorBlockAtEnder); ok {
@@ -104,6 +177,16 @@ func NewRstrings(list []string, behavior interface{}) (rdr Rstrings) {
 			bkb.BehaviorBlockBeforeEachRead()
 		}
 	}
+	if bae, ok := behavior.(BehaviorAfterElementer); ok {
+		rdr.afterElem = bae.BehaviorAfterElement
+	}
+	if be, ok := behavior.(BehaviorEchoer); ok {
+		rdr.echo = be.BehaviorEcho()
+	}
+	if bit, ok := behavior.(BehaviorIdleTimeouter); ok {
+		rdr.idle = bit.BehaviorIdleTimeout()
+		rdr.clock = clockOf(behavior)
+	}
 	return rdr
 }
 
@@ -112,39 +195,100 @@ Read implements an io.Reader based on a slice of strings conforming to
 io.Reader semantics (https://golang.org/pkg/io/#Reader).
 */
 func (m *Rstrings) Read(p []byte) (int, error) {
-	if len(p) == 0 {
-		// if blocking before read want to return before blocking
-		// when requesting 0 bytes - do nothing.
-		return 0, nil
+	n, err := m.read(p)
+	if m.echo != nil && n > 0 {
+		m.echo.Write(p[:n])
 	}
-	m.blockBefore()
-	var pi int
-	for ; m.lcur < len(m.list); m.lcur++ {
-		for ; m.ccur < len(m.list[m.lcur]); m.ccur++ {
-			if pi < len(p) {
-				p[pi] = ([]byte(m.list[m.lcur]))[m.ccur]
-				pi++
-			} else {
-				return len(p), nil
-			}
-		}
-		for ; m.dcur < len(m.delim); m.dcur++ {
-			if pi < len(p) {
-				p[pi] = m.delim[m.dcur]
-				pi++
-			} else {
-				return len(p), nil
-			}
-		}
-		m.dcur = 0
-		m.ccur = 0
+	return n, err
+}
+
+/*
+ReadAt implements an io.ReaderAt (https://golang.org/pkg/io/#ReaderAt) that
+treats the list of strings, including their delimiters, as a single flat
+region of bytes.  It neither affects nor is affected by the position of
+the sequential Read cursor and never executes blocking behaviors.
+*/
+func (m *Rstrings) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, errors.New("mckio.Rstrings.ReadAt: negative offset")
 	}
-	if pi < 1 {
-		m.block()
-		// if block Behavior doesn't block then return EOF
-		return 0, io.EOF
+	var pos int64
+	for i := 0; i < len(m.list) && n < len(p); i++ {
+		n, pos = readAtSegment(p, n, off, pos, m.element(i))
+		n, pos = readAtSegment(p, n, off, pos, string(m.delimAt(i)))
 	}
-	return pi, nil
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+/*
+Len returns the number of bytes not yet consumed by Read, including
+delimiters.
+*/
+func (m *Rstrings) Len() (remain int) {
+	for i := m.lcur; i < len(m.list); i++ {
+		remain += len(m.element(i)) + len(m.delimAt(i))
+	}
+	if m.lcur < len(m.list) {
+		remain -= m.ccur + m.dcur
+	}
+	return remain
+}
+
+/*
+Size returns the total number of bytes represented by the list of strings,
+including delimiters.  It's unaffected by Read.
+*/
+func (m *Rstrings) Size() (total int64) {
+	for i := range m.list {
+		total += int64(len(m.element(i)) + len(m.delimAt(i)))
+	}
+	return total
+}
+
+/*
+DebugState reports the position of the read cursor and whether Read is
+currently executing a blocking behavior.  Although Rstrings isn't
+concurrency safe, DebugState may be called from another goroutine while
+Read is blocked in a blocking behavior, as the cursor doesn't change then.
+Otherwise, calls must be synchronized with Read, since the cursor isn't
+stored atomically.
+*/
+func (m *Rstrings) DebugState() ReaderState {
+	return ReaderState{
+		Element: m.lcur,
+		Offset:  m.ccur,
+		Delim:   m.dcur,
+		Blocked: atomic.LoadInt32(&m.blocked) == 1,
+	}
+}
+
+/*
+String implements fmt.Stringer reporting the DebugState of Rstrings.
+*/
+func (m *Rstrings) String() string {
+	return fmt.Sprintf("Rstrings{element: %d of %d, %s}", m.lcur, len(m.list), m.DebugState().fields())
+}
+
+/*
+ReaderState describes the read cursor of a mock reader to help diagnose
+failing tests.
+
+- Element - index of the string or message currently being read.
+
+- Offset - byte offset within the current element.
+
+- Delim - byte offset within the delimiter that follows the element.
+
+- Blocked - true while Read is blocked.
+*/
+type ReaderState struct {
+	Element int
+	Offset  int
+	Delim   int
+	Blocked bool
 }
 
 /*
@@ -158,7 +302,16 @@ simulation by composing:
 - BehaviorBlockAtEnd - executing a block after exhausting the list of provided strings.
 */
 func NewConsole(cmdLns []string) (rdr Rstrings) {
-	return NewRstrings(cmdLns, stdin{})
+	return NewConsoleClock(cmdLns, SystemClock())
+}
+
+/*
+NewConsoleClock simulates os.Stdin like NewConsole, measuring the 1 second
+delay before each read with 'clock', so a FakeClock can release reads
+without waiting.
+*/
+func NewConsoleClock(cmdLns []string, clock Clock) (rdr Rstrings) {
+	return NewRstrings(cmdLns, stdin{clock: clock})
 }
 
 /*
@@ -182,6 +335,59 @@ receive a quantity of bytes less than the length requested by the
 
 - Closing the channel returns an io.EOF error.
 
+The following behavior of Rchan can be configured:
+
+- BehaviorContexter (optional) - specifies a context whose cancellation
+aborts a Read blocked receiving from the channel.  The aborted Read returns
+the context's error.  When undefined - Read blocks until the channel
+delivers a message or is closed.
+
+- BehaviorCancelErrer (optional) - specifies the error returned by a Read
+aborted by BehaviorContexter.  When undefined - the context's error is returned.
+
+- BehaviorCloseDiscarder (optional) - specifies whether messages received
+but not yet consumed are discarded once the channel is closed.  When
+undefined - they're delivered before returning io.EOF.
+
+- BehaviorDelimer or BehaviorDelimFuncer (optional) - specifies the
+delimiter concatenated to the end of each received string.  The index
+supplied to BehaviorDelimFunc is the message's ordinal position in the
+stream.  When undefined - no concatenation occurs.
+
+- BehaviorNonBlocker (optional) - specifies whether Read performs
+non-blocking receives.  When true, Read drains every message currently
+buffered by the channel into 'p' and returns immediately, returning 0, nil
+when no message is available.  When undefined - Read blocks until
+receiving a message.
+
+- BehaviorCloseErrer (optional) - specifies the error returned by Read
+after Close is called.  When undefined - os.ErrClosed is returned.
+
+- BehaviorBlockBeforeEachReader (optional) - specifies an implementation
+blocking the reader before it attempts to read.  When undefined - the read
+immediately executes.
+
+- BehaviorBlockAtEnder (optional) - specifies an implementation that blocks
+the reader once the channel is closed and its messages consumed instead of
+signaling io.EOF.  When undefined - signals io.EOF.
+
+- BehaviorChunkSizer (optional) - limits the number of bytes returned by
+each Read, so a long message is deterministically fragmented across
+several reads.  When undefined - Read returns as many bytes of the current
+message as 'p' accommodates.
+
+- BehaviorWaitNotifier (optional) - supplies a channel signaled each time
+Read waits on an empty channel, so a producer can detect the reader's
+readiness instead of sleeping.  When undefined - waiting isn't signaled.
+
+- BehaviorClocker (optional) - supplies the Clock measuring the deadline set
+by SetReadDeadline.  When undefined - uses the system clock.
+
+Stats reports the number of messages received and bytes delivered by Read.
+SetReadDeadline limits the time Read waits for the channel to deliver a
+message, mirroring net.Conn semantics.  Close aborts the reader without
+closing its channel.
+
 Note
 
 - Although golang defines a string as "just a bunch of bytes" use caution
@@ -193,16 +399,217 @@ compatible to the component consuming the bytes returned by io.Read
 - Rchan is not concurrency safe.
 */
 type Rchan struct {
-	cmdLn <-chan string
-	sCur  string
-	spos  int
+	cmdLn       <-chan string
+	sCur        string
+	spos        int
+	msgs        int
+	blocked     int32
+	ctx         context.Context
+	cancelErr   error
+	discard     bool
+	prefetch    []string
+	closed      bool
+	deadline    *readDeadline
+	errs        <-chan error
+	delim       func(index int, s string) []byte
+	dCur        []byte
+	dpos        int
+	nonBlock    bool
+	pendErr     error
+	abort       *abortSignal
+	chunk       int
+	blockBefore func()
+	block       func()
+	waitNotify  chan<- struct{}
+	statMsgs    int64
+	statBytes   int64
+}
+
+/*
+BehaviorContexter supplies a context whose cancellation aborts blocking
+operations.  Use it to avoid leaking goroutines blocked forever on a
+stalled producer after a test finishes.
+*/
+type BehaviorContexter interface {
+	BehaviorContext() context.Context
+}
+
+/*
+BehaviorCancelErrer supplies the error returned by an operation aborted
+due to the cancellation of the context provided by BehaviorContexter.
+*/
+type BehaviorCancelErrer interface {
+	BehaviorCancelErr() error
+}
+
+/*
+BehaviorCloseDiscarder selects the shutdown semantics of a channel reader.
+When BehaviorCloseDiscard returns true, messages already buffered by
+the channel are prefetched whenever a message is received.  If the
+prefetch discovers the channel was closed, the prefetched messages, as well
+as the unread portion of the current message, are discarded and the
+reader immediately returns io.EOF.  Otherwise, the reader drains every
+message before returning io.EOF.  Both behaviors exist in real drivers.
+*/
+type BehaviorCloseDiscarder interface {
+	BehaviorCloseDiscard() bool
+}
+
+/*
+BehaviorNonBlocker specifies whether a reader polls, instead of blocks, when
+waiting for input.  It lets tests poll simulated input without dedicating
+a goroutine to the reader.
+*/
+type BehaviorNonBlocker interface {
+	BehaviorNonBlock() bool
+}
+
+/*
+BehaviorCloseErrer supplies the error returned by operations on a mock
+after it's been closed.
+*/
+type BehaviorCloseErrer interface {
+	BehaviorCloseErr() error
+}
+
+/*
+BehaviorChunkSizer limits the number of bytes returned by a single Read,
+which is useful for testing reassembly logic downstream.
+*/
+type BehaviorChunkSizer interface {
+	BehaviorChunkSize() int
+}
+
+/*
+BehaviorWaitNotifier supplies a channel that Rchan signals whenever Read
+waits for the channel to deliver a message.  The signal is sent without
+blocking, therefore, a notification is dropped when the channel isn't
+ready to accept it.  A channel buffered to one element retains a pending
+notification until the producer consumes it.
+*/
+type BehaviorWaitNotifier interface {
+	BehaviorWaitNotify() chan<- struct{}
+}
+
+/*
+RchanStats reports the activity of an Rchan.
+
+- Messages - number of messages received from the channel.
+
+- Bytes - number of bytes, including delimiters, returned by Read.
+*/
+type RchanStats struct {
+	Messages int64
+	Bytes    int64
 }
 
 /*
 NewChan creates an io.Reader implemented as a receiving channel of strings.
 */
 func NewChan(cmdLn <-chan string) (rdr Rchan) {
-	return Rchan{cmdLn: cmdLn}
+	return NewChanBehavior(cmdLn, nil)
+}
+
+/*
+NewChanBehavior creates an io.Reader implemented as a receiving channel of
+strings whose behavior can be configured using BehaviorContexter,
+BehaviorCancelErrer, BehaviorCloseDiscarder, BehaviorDelimer or
+BehaviorDelimFuncer, BehaviorNonBlocker, BehaviorCloseErrer,
+BehaviorChunkSizer, BehaviorBlockBeforeEachReader, BehaviorBlockAtEnder,
+BehaviorWaitNotifier, and BehaviorClocker.
+These are the same behavior interfaces accepted by NewRstrings, where
+applicable.
+*/
+func NewChanBehavior(cmdLn <-chan string, behavior interface{}) (rdr Rchan) {
+	rdr.cmdLn = cmdLn
+	rdr.deadline = newReadDeadline(clockOf(behavior))
+	rdr.abort = newAbortSignal(os.ErrClosed)
+	if bc, ok := behavior.(BehaviorContexter); ok {
+		rdr.ctx = bc.BehaviorContext()
+	}
+	if bce, ok := behavior.(BehaviorCancelErrer); ok {
+		rdr.cancelErr = bce.BehaviorCancelErr()
+	}
+	if bcd, ok := behavior.(BehaviorCloseDiscarder); ok {
+		rdr.discard = bcd.BehaviorCloseDiscard()
+	}
+	if pd, ok := behavior.(BehaviorDelimer); ok {
+		delim := pd.BehaviorDelim()
+		rdr.delim = func(int, string) []byte { return delim }
+	}
+	if pdf, ok := behavior.(BehaviorDelimFuncer); ok {
+		rdr.delim = pdf.BehaviorDelimFunc
+	}
+	if bnb, ok := behavior.(BehaviorNonBlocker); ok {
+		rdr.nonBlock = bnb.BehaviorNonBlock()
+	}
+	if bce, ok := behavior.(BehaviorCloseErrer); ok {
+		rdr.abort.err = bce.BehaviorCloseErr()
+	}
+	if bcs, ok := behavior.(BehaviorChunkSizer); ok {
+		rdr.chunk = bcs.BehaviorChunkSize()
+	}
+	if bwn, ok := behavior.(BehaviorWaitNotifier); ok {
+		rdr.waitNotify = bwn.BehaviorWaitNotify()
+	}
+	rdr.block = func() {}
+	if bk, ok := behavior.(BehaviorBlockAtEnder); ok {
+		rdr.block = func() {
+			bk.BehaviorBlockAtEnd()
+		}
+	}
+	rdr.blockBefore = func() {}
+	if bkb, ok := behavior.(BehaviorBlockBeforeEachReader); ok {
+		rdr.blockBefore = func() {
+			bkb.BehaviorBlockBeforeEachRead()
+		}
+	}
+	return rdr
+}
+
+/*
+NewChanWithErrs creates an io.Reader implemented as a receiving channel of
+strings accompanied by a channel of errors.  An error received from 'errs'
+is returned by the Read waiting for the next message, letting producers
+inject transient or fatal read errors at precise points in the stream.
+
+- Residual bytes of the current message are returned before receiving
+an error.
+
+- To precisely order errors relative to messages, use unbuffered channels
+and send from a single producer goroutine, as the reader chooses randomly
+when both channels are ready.
+
+- Closing 'errs' stops error delivery without affecting 'data'.
+*/
+func NewChanWithErrs(data <-chan string, errs <-chan error) (rdr Rchan) {
+	rdr = NewChan(data)
+	rdr.errs = errs
+	return rdr
+}
+
+/*
+NewChanPush creates an Rchan that owns its channel, so tests needn't manage
+one.  Messages are sent to the reader by calling 'push' and 'end' closes the
+channel, causing the reader to return io.EOF once it consumes the messages
+already pushed.  Calling 'end' more than once is harmless, however, calling
+'push' after 'end' panics, as does sending on a closed channel.
+
+- depth - specifies the channel's buffer size.  A depth of 0 creates an
+unbuffered channel, so push blocks until the reader receives the message,
+while a positive depth permits the producer to run ahead of the reader by
+that many messages.
+*/
+func NewChanPush(depth int) (rdr Rchan, push func(msg string), end func()) {
+	cmdLn := make(chan string, depth)
+	var once sync.Once
+	push = func(msg string) {
+		cmdLn <- msg
+	}
+	end = func() {
+		once.Do(func() { close(cmdLn) })
+	}
+	return NewChan(cmdLn), push, end
 }
 
 /*
@@ -215,25 +622,127 @@ func (rc *Rchan) Read(p []byte) (int, error) {
 		// of blocking and then returning nothing.
 		return 0, nil
 	}
-	var ip int
-	for {
-		for ; rc.spos < len(rc.sCur) && ip < len(p); rc.spos, ip = rc.spos+1, ip+1 {
-			p[ip] = ([]byte(rc.sCur))[rc.spos]
-		}
-		if ip > 0 {
-			// have something to return.  do so before
-			// possibly blocking on channel.
-			return ip, nil
-		}
-		var ok bool
-		rc.sCur, ok = <-rc.cmdLn
-		if !ok {
-			return 0, io.EOF
-		}
-		rc.spos = 0
+	blocking(&rc.blocked, rc.blockBefore)
+	n, err := rc.read(p)
+	atomic.AddInt64(&rc.statBytes, int64(n))
+	if err == io.EOF {
+		blocking(&rc.blocked, rc.block)
+		// if block Behavior doesn't block then return EOF
+	}
+	return n, err
+}
+
+/*
+SetReadDeadline sets the deadline for future Read calls and any currently
+blocked Read, mirroring net.Conn semantics.  A Read waiting for the channel
+to deliver a message beyond the deadline returns os.ErrDeadlineExceeded.
+Residual bytes of a previously received message are returned regardless of
+the deadline.  A zero value for t means Read will not time out.  Unlike
+other Rchan methods, SetReadDeadline may be called from another goroutine.
+*/
+func (rc *Rchan) SetReadDeadline(t time.Time) error {
+	rc.deadline.set(t)
+	return nil
+}
+
+/*
+Close aborts the reader without closing its channel, which may be shared
+with other consumers.  Any blocked or future Read returns the error
+specified by BehaviorCloseErrer.  Close may be called from another
+goroutine and more than once.
+*/
+func (rc *Rchan) Close() error {
+	rc.abort.signal()
+	return nil
+}
+
+/*
+Stats reports the number of messages received and bytes delivered by Rchan.
+Stats may be called from another goroutine.
+*/
+func (rc *Rchan) Stats() RchanStats {
+	return RchanStats{
+		Messages: atomic.LoadInt64(&rc.statMsgs),
+		Bytes:    atomic.LoadInt64(&rc.statBytes),
 	}
 }
 
+/*
+DebugState reports the position of the read cursor and whether Read is
+blocked waiting on the channel.  Element is the index of the most recently
+received message, -1 before receiving one.  Although Rchan isn't concurrency
+safe, DebugState may be called from another goroutine while Read is blocked.
+*/
+func (rc *Rchan) DebugState() ReaderState {
+	return ReaderState{
+		Element: rc.msgs - 1,
+		Offset:  rc.spos,
+		Delim:   rc.dpos,
+		Blocked: atomic.LoadInt32(&rc.blocked) == 1,
+	}
+}
+
+/*
+String implements fmt.Stringer reporting the DebugState of Rchan.
+*/
+func (rc *Rchan) String() string {
+	return fmt.Sprintf("Rchan{element: %d, %s}", rc.msgs-1, rc.DebugState().fields())
+}
+
+/*
+BehaviorCaptureTeer specifies whether a capture forwards the output it
+captures to the original file too.
+*/
+type BehaviorCaptureTeer interface {
+	BehaviorCaptureTee() bool
+}
+
+/*
+BehaviorCaptureDester specifies a writer receiving captured output in lieu
+of the capture's memory buffer.
+*/
+type BehaviorCaptureDester interface {
+	BehaviorCaptureDest() io.Writer
+}
+
+/*
+BehaviorCaptureAnsiStripper specifies whether escape sequences, like color
+codes, are stripped from captured output.
+*/
+type BehaviorCaptureAnsiStripper interface {
+	BehaviorCaptureAnsiStrip() bool
+}
+
+/*
+BehaviorCaptureMaxDurationer specifies the duration after which a capture
+stops recording output.  The variable remains redirected until the capture
+is ended.
+*/
+type BehaviorCaptureMaxDurationer interface {
+	BehaviorCaptureMaxDuration() time.Duration
+}
+
+/*
+BehaviorOnWriter specifies a function observing each chunk of captured
+output as it's captured.
+*/
+type BehaviorOnWriter interface {
+	BehaviorOnWrite(p []byte)
+}
+
+/*
+WithOnWrite implements BehaviorOnWriter by calling itself.  Embed it in a
+behavior struct to combine it with other behaviors.
+*/
+type WithOnWrite func(p []byte)
+
+/*
+BehaviorOnWrite calls the function with 'p'.
+*/
+func (fn WithOnWrite) BehaviorOnWrite(p []byte) {
+	fn(p)
+}
+
 /*
 FileCaptureStart redirects and captures write operations targeted to a
 file.  The content of these write operations are buffered in memory
@@ -258,18 +767,474 @@ func FileCaptureStart(
 	captureEnd func(), // execute this function to terminate capturing and revert variable to its original value.
 	err error,
 ) {
-	// control bus signals stop capturing output.  caller participates as
-	// sender on control bus. caller uses returned function to send
-	// capture end signal to this receiver (capture agent) that's
-	// redirecting file output.
-	var capCtrl bus.B
-	stopCapture, captureStop, _ := capCtrl.SenderConnect()
-	captureEnd = ctrlCapture(stopCapture, captureStop)
-	endCapture := capCtrl.ReceiverConnect()
-	// data bus delivers captured output to caller. caller participates as
-	// receiver while this capture agent performs role as sender.
-	var capOut bus.B
-	pipeSender, dscnnt, _ := capOut.SenderConnect()
+	return FileCaptureStartBehavior(osf, nil)
+}
+
+/*
+FileCaptureStartBehavior captures write operations targeted to a file, like
+FileCaptureStart, whose behavior can be configured:
+
+- BehaviorMemoryGuarder (optional) - limits the number of bytes buffered
+while capturing.  Once exceeded, the guard is notified and the remaining
+output is discarded, so the captured content is truncated to the limit.
+When undefined - buffering is unlimited.
+
+- BehaviorCaptureLimiter (optional) - retains only the first and/or last
+bytes of the captured output, discarding the bytes in between, so long
+soak tests don't exhaust memory.  When defined, it supersedes
+BehaviorMemoryGuarder.  When undefined - everything is retained.
+
+- BehaviorCaptureTeer (optional) - specifies whether captured output is
+also forwarded to the original file, so it remains visible, for example on
+the terminal, while debugging.  When undefined - output is only captured.
+
+- BehaviorCaptureDester (optional) - streams captured output into a writer,
+like a file or network sink, instead of buffering it, so huge or binary
+output is handled without conversion to a string.  Since nothing is
+buffered, no output is delivered via the channel.  When defined, it
+supersedes BehaviorCaptureLimiter and BehaviorMemoryGuarder.  When
+undefined - output is buffered.
+
+- BehaviorCaptureAnsiStripper (optional) - specifies whether ANSI/VT100
+escape sequences are stripped from the captured output, like Wansi, before
+it's processed by the remaining behaviors, so assertions needn't account for
+styling.  Forwarding by BehaviorCaptureTeer retains them.  When undefined -
+escape sequences are captured.
+
+- BehaviorKeepRawer (optional) - when stripping escape sequences, specifies
+whether the unstripped output is captured too.  Reported by Capture.Raw.
+When undefined - the unstripped output isn't kept.
+
+- BehaviorDelimer (optional) - splits the captured output into records
+terminated by the delimiter, like "\x00" or "\n---\n", each delivered
+separately via the channel, excluding its delimiter.  An unterminated final
+record is delivered too.  When undefined - the output is delivered as a
+single string.
+
+- BehaviorCaptureMaxDurationer (optional) - once the duration elapses,
+delivers the output captured so far and discards the output written
+afterwards, so a test of a program that may hang retains the output
+instead of deadlocking.  The variable isn't restored until captureEnd is
+called, as the program may still be writing it.  captureEnd then returns
+an error wrapping os.ErrDeadlineExceeded, when it can report one.  When
+undefined - capturing continues until ended.
+
+- BehaviorClocker (optional) - supplies the Clock measuring the duration
+specified by BehaviorCaptureMaxDurationer.  When undefined - uses the
+system clock.
+
+- BehaviorOnWriter (optional) - invoked synchronously with each chunk of
+captured output, before the next chunk is captured, so a test can react to
+output in real time, for example, by feeding the next line of input once a
+prompt appears.  A chunk usually mirrors a write, although writes can be
+merged or split.  'p' is only valid during the call.  When undefined - no
+function is invoked.
+
+A failure copying the captured output truncates it.  Use FileCaptureStartErr
+to observe the failure.
+*/
+func FileCaptureStartBehavior(
+	osf **os.File, // provide address to variable containing pointer to os.file.
+	behavior interface{}, // specify optional behaviors.
+) (
+	output <-chan string, // output content of all write operations as string.
+	captureEnd func(), // execute this function to terminate capturing and revert variable to its original value.
+	err error,
+) {
+	output, captureEndErr, err := fileCaptureStart(osf, behavior, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return output, func() { captureEndErr() }, nil
+}
+
+/*
+FileCaptureStartErr captures write operations targeted to a file, like
+FileCaptureStartBehavior, although its captureEnd function reports a failure
+encountered while copying the captured output, instead of discarding it.
+The output captured before the failure remains available via the channel.
+
+- Unlike FileCaptureStartBehavior, captureEnd waits until the captured output
+has been copied.
+*/
+func FileCaptureStartErr(
+	osf **os.File, // provide address to variable containing pointer to os.file.
+	behavior interface{}, // specify optional behaviors.
+) (
+	output <-chan string, // output content of all write operations as string.
+	captureEnd func() error, // terminates capturing, reverts variable to its original value and reports copy failures.
+	err error,
+) {
+	return fileCaptureStart(osf, behavior, true)
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+func (rs ReaderState) fields() string {
+	return fmt.Sprintf("offset: %d, delim: %d, blocked: %t", rs.Offset, rs.Delim, rs.Blocked)
+}
+
+func (m *Rstrings) readBlocked() bool {
+	return atomic.LoadInt32(&m.blocked) == 1
+}
+func (rc *Rchan) readBlocked() bool {
+	return atomic.LoadInt32(&rc.blocked) == 1
+}
+
+// indicates a blocking function is executing.
+func blocking(blocked *int32, block func()) {
+	atomic.StoreInt32(blocked, 1)
+	defer atomic.StoreInt32(blocked, 0)
+	block()
+}
+
+func (m *Rstrings) read(p []byte) (int, error) {
+	if len(p) == 0 {
+		// if blocking before read want to return before blocking
+		// when requesting 0 bytes - do nothing.
+		return 0, nil
+	}
+	m.notifyConsumed()
+	blocking(&m.blocked, m.blockBefore)
+	var pi int
+	for ; m.lcur < len(m.list); m.lcur++ {
+		if m.list[m.lcur] == ScriptEOF {
+			if pi > 0 {
+				// deliver the preceding bytes before end of file.
+				return pi, nil
+			}
+			m.lcur++
+			return 0, io.EOF
+		}
+		for ; m.ccur < len(m.list[m.lcur]); m.ccur++ {
+			if pi < len(p) {
+				p[pi] = ([]byte(m.list[m.lcur]))[m.ccur]
+				pi++
+			} else {
+				return len(p), nil
+			}
+		}
+		delim := m.delimAt(m.lcur)
+		for ; m.dcur < len(delim); m.dcur++ {
+			if pi < len(p) {
+				p[pi] = delim[m.dcur]
+				pi++
+			} else {
+				return len(p), nil
+			}
+		}
+		m.dcur = 0
+		m.ccur = 0
+	}
+	if pi < 1 {
+		if m.clock != nil {
+			return 0, m.idleTimeout()
+		}
+		blocking(&m.blocked, m.block)
+		// if block Behavior doesn't block then return EOF
+		return 0, io.EOF
+	}
+	return pi, nil
+}
+
+// notifies the elements consumed by previous reads.
+func (m *Rstrings) notifyConsumed() {
+	for ; m.notified < m.lcur; m.notified++ {
+		if m.afterElem != nil {
+			m.afterElem(m.notified)
+		}
+	}
+}
+
+// the content of the element at index, which is empty for a marker.
+func (m *Rstrings) element(index int) string {
+	if m.list[index] == ScriptEOF {
+		return ""
+	}
+	return m.list[index]
+}
+func (m *Rstrings) delimAt(index int) []byte {
+	if m.delims == nil || m.list[index] == ScriptEOF {
+		return nil
+	}
+	return m.delims[index]
+}
+
+// copies the portion of segment, located at pos within the flat region,
+// that overlaps the requested offset.
+func readAtSegment(p []byte, n int, off int64, pos int64, seg string) (int, int64) {
+	end := pos + int64(len(seg))
+	if start := off + int64(n); n < len(p) && start < end {
+		n += copy(p[n:], seg[start-pos:])
+	}
+	return n, end
+}
+func delimsFixed(list []string, delim []byte) (delims [][]byte) {
+	delims = make([][]byte, len(list))
+	for i := range delims {
+		delims[i] = delim
+	}
+	return delims
+}
+
+// computed once, when constructing the reader, so a stateful
+// function is consulted exactly once per element.
+func delimsCompute(list []string, delimFn func(index int, s string) []byte) (delims [][]byte) {
+	delims = make([][]byte, len(list))
+	for i, s := range list {
+		delims[i] = delimFn(i, s)
+	}
+	return delims
+}
+
+// fills p with residual bytes and messages available without blocking.
+// an error encountered after filling part of p is returned by the next read.
+func (rc *Rchan) drain(p []byte) (int, error) {
+	var ip int
+	for {
+		ip += rc.fill(p[ip:])
+		if ip == len(p) {
+			return ip, nil
+		}
+		received, err := rc.poll()
+		if err != nil && ip > 0 {
+			if err != io.EOF {
+				rc.pendErr = err
+			}
+			return ip, nil
+		}
+		if err != nil || !received {
+			return ip, err
+		}
+	}
+}
+
+// non-blocking version of receive.
+func (rc *Rchan) poll() (received bool, err error) {
+	if done, err := rc.receivePrefetched(); done {
+		return err == nil, err
+	}
+	for {
+		select {
+		case msg, ok := <-rc.cmdLn:
+			err := rc.received(msg, ok)
+			return err == nil, err
+		case err, eok := <-rc.errs:
+			if eok {
+				return false, err
+			}
+			rc.errs = nil
+		default:
+			return false, nil
+		}
+	}
+}
+func (rc *Rchan) receivePrefetched() (done bool, err error) {
+	if len(rc.prefetch) > 0 {
+		rc.next(rc.prefetch[0])
+		rc.prefetch = rc.prefetch[1:]
+		return true, nil
+	}
+	if rc.closed {
+		return true, io.EOF
+	}
+	return false, nil
+}
+func (rc *Rchan) read(p []byte) (int, error) {
+	if rc.chunk > 0 && len(p) > rc.chunk {
+		p = p[:rc.chunk]
+	}
+	if rc.abort.aborted() {
+		return 0, rc.abort.err
+	}
+	if rc.closed {
+		// channel closure discards residual bytes
+		return 0, io.EOF
+	}
+	if rc.pendErr != nil {
+		err := rc.pendErr
+		rc.pendErr = nil
+		return 0, err
+	}
+	if rc.nonBlock {
+		return rc.drain(p)
+	}
+	var ip int
+	for {
+		ip += rc.fill(p[ip:])
+		if ip > 0 {
+			// have something to return.  do so before
+			// possibly blocking on channel.
+			return ip, nil
+		}
+		if err := rc.receive(); err != nil {
+			return 0, err
+		}
+	}
+}
+func (rc *Rchan) receive() error {
+	if done, err := rc.receivePrefetched(); done {
+		return err
+	}
+	atomic.StoreInt32(&rc.blocked, 1)
+	defer atomic.StoreInt32(&rc.blocked, 0)
+	rc.notifyWait()
+	var done <-chan struct{}
+	if rc.ctx != nil {
+		done = rc.ctx.Done()
+	}
+	var msg string
+	var ok bool
+	for received := false; !received; {
+		expire, changed, stop := rc.deadline.timer()
+		select {
+		case msg, ok = <-rc.cmdLn:
+			received = true
+		case <-done:
+			stop()
+			if rc.cancelErr != nil {
+				return rc.cancelErr
+			}
+			return rc.ctx.Err()
+		case <-expire:
+			return os.ErrDeadlineExceeded
+		case <-rc.abort.done:
+			stop()
+			return rc.abort.err
+		case err, eok := <-rc.errs:
+			stop()
+			if eok {
+				return err
+			}
+			// closed error channel no longer delivers errors.
+			rc.errs = nil
+			continue
+		case <-changed:
+			// deadline reset while waiting, reevaluate it.
+		}
+		stop()
+	}
+	return rc.received(msg, ok)
+}
+func (rc *Rchan) received(msg string, ok bool) error {
+	if !ok {
+		return io.EOF
+	}
+	if rc.discard && rc.prefetchClosed() {
+		return io.EOF
+	}
+	rc.next(msg)
+	return nil
+}
+
+// copies residual bytes of the current message, followed by its delimiter,
+// into p.
+func (rc *Rchan) fill(p []byte) int {
+	n := copy(p, rc.sCur[rc.spos:])
+	rc.spos += n
+	nd := copy(p[n:], rc.dCur[rc.dpos:])
+	rc.dpos += nd
+	return n + nd
+}
+func (rc *Rchan) next(msg string) {
+	rc.sCur = msg
+	rc.spos = 0
+	rc.dCur, rc.dpos = nil, 0
+	if rc.delim != nil {
+		rc.dCur = rc.delim(rc.msgs, msg)
+	}
+	rc.msgs++
+	atomic.AddInt64(&rc.statMsgs, 1)
+}
+
+// signals a waiting reader unless a message is already pending.
+func (rc *Rchan) notifyWait() {
+	if rc.waitNotify == nil || len(rc.cmdLn) > 0 {
+		return
+	}
+	select {
+	case rc.waitNotify <- struct{}{}:
+	default:
+	}
+}
+
+// receives messages already buffered by the channel without blocking.
+// returns true when the channel is closed, discarding the prefetched
+// messages.
+func (rc *Rchan) prefetchClosed() bool {
+	for {
+		select {
+		case msg, ok := <-rc.cmdLn:
+			if !ok {
+				rc.closed = true
+				rc.prefetch = nil
+				rc.sCur, rc.spos = "", 0
+				rc.dCur, rc.dpos = nil, 0
+				return true
+			}
+			rc.prefetch = append(rc.prefetch, msg)
+		default:
+			return false
+		}
+	}
+}
+
+// signals operations to abort.  shared by copies of a mock, so it may be
+// signaled while an operation is blocked in another goroutine.
+type abortSignal struct {
+	once sync.Once
+	done chan struct{}
+	err  error
+}
+
+func newAbortSignal(err error) *abortSignal {
+	return &abortSignal{done: make(chan struct{}), err: err}
+}
+func (as *abortSignal) signal() {
+	as.once.Do(func() { close(as.done) })
+}
+func (as *abortSignal) aborted() bool {
+	select {
+	case <-as.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// deadline shared by copies of a reader, so it may be set while Read is
+// blocked in another goroutine.
+type readDeadline struct {
+	mu      sync.Mutex
+	t       time.Time
+	changed chan struct{}
+	clock   Clock
+}
+
+func newReadDeadline(clock Clock) *readDeadline {
+	return &readDeadline{changed: make(chan struct{}), clock: clock}
+}
+func (rd *readDeadline) set(t time.Time) {
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+	rd.t = t
+	close(rd.changed)
+	rd.changed = make(chan struct{})
+}
+
+// returns a channel signaling the deadline's expiration, which is nil when
+// no deadline is set, and a channel signaling the deadline was changed.
+func (rd *readDeadline) timer() (expire <-chan time.Time, changed <-chan struct{}, stop func()) {
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+	if rd.t.IsZero() {
+		return nil, rd.changed, func() {}
+	}
+	tmr := rd.clock.NewTimer(rd.t.Sub(rd.clock.Now()))
+	return tmr.C(), rd.changed, func() { tmr.Stop() }
+}
+func fileCaptureStart(osf **os.File, behavior interface{}, waitCopy bool) (<-chan string, func() error, error) {
 	rdr, wrt, errp := os.Pipe()
 	if errp != nil {
 		return nil, nil, errp
@@ -282,57 +1247,240 @@ func FileCaptureStart(
 	// same concurrent unit of the caller, so statements that follow this
 	// function's invocation should be affected by the change.
 	*osf = wrt
-	go wfilePipe(osf, file, rdr, wrt, pipeSender, dscnnt, endCapture)
+	buf := newCaptureBuffer(behavior, file)
+	copied := &captureCopy{done: make(chan struct{})}
+	var expire Timer
+	d := captureMaxDuration(behavior)
+	if d > 0 {
+		expire = clockOf(behavior).NewTimer(d)
+	}
+	// delivers captured output to caller once the pipe is drained or the
+	// capture expires.
 	out := make(chan string)
-	go cvrtToStringChan(capOut.ReceiverConnect(), out)
+	go wfileCapture(rdr, buf, out, copied, expire)
+	var once sync.Once
+	captureEnd := func() error {
+		once.Do(func() {
+			// close write end of pipe which eventually signals
+			// end of file on the pipe's read side.
+			wrt.Close()
+			*osf = file
+		})
+		if !waitCopy {
+			return nil
+		}
+		<-copied.done
+		if copied.err == nil && copied.isExpired() {
+			return captureExpiredErr(d)
+		}
+		return copied.err
+	}
 	return out, captureEnd, nil
 }
+func captureMaxDuration(behavior interface{}) time.Duration {
+	if bmd, ok := behavior.(BehaviorCaptureMaxDurationer); ok {
+		return bmd.BehaviorCaptureMaxDuration()
+	}
+	return 0
+}
+func captureExpiredErr(d time.Duration) error {
+	return fmt.Errorf("mckio: capture exceeded its maximum duration of %v: %w", d, os.ErrDeadlineExceeded)
+}
 
-//-----------------------------------------------------------------------------
-//--                         Private Section                                ---
-//-----------------------------------------------------------------------------
-func ctrlCapture(stopCapture chan<- interface{}, busDscnnt func()) (captureEnd func()) {
-	return func() {
-		// prevent premature close of pipe
-		stopCapture <- true
-		// ensure pipe closed & os.file reverted before returning from this function.
-		stopCapture <- true
-		// disconnect from control bus
-		busDscnnt()
-	}
-}
-func wfilePipe(osf **os.File, file *os.File, rdr *os.File, wrt *os.File, sender chan<- interface{}, dscnnt func(), endCapture <-chan interface{}) {
-	go wfileCapture(rdr, sender, dscnnt)
-	// caller receiving capture output issues request to stop
-	// its recording.
-	<-endCapture
-	// close write end of pipe which eventually signals
-	// end of file on the pipe's read side.
-	wrt.Close()
-	*osf = file
-	// ensure above occurs before end capture closure terminates - happens before.
-	<-endCapture
-}
-func wfileCapture(rdr *os.File, capture chan<- interface{}, dscnnt func()) {
-	defer dscnnt()
-	var buf bytes.Buffer
-	sz, err := io.Copy(&buf, rdr)
-	if err != nil {
-		panic(err)
+// outcome of copying the captured output from the pipe.  err is valid once
+// done is closed.  once expired, the output copied is discarded.
+type captureCopy struct {
+	done    chan struct{}
+	err     error
+	mu      sync.Mutex
+	expired bool
+}
+
+func (cc *captureCopy) expire() {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.expired = true
+}
+func (cc *captureCopy) isExpired() bool {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return cc.expired
+}
+
+// discards writes once the capture expired, so the output delivered isn't
+// changed by the copy that continues draining the pipe.
+type captureGate struct {
+	copied *captureCopy
+	dest   io.Writer
+}
+
+func (cg captureGate) Write(p []byte) (int, error) {
+	cg.copied.mu.Lock()
+	defer cg.copied.mu.Unlock()
+	if cg.copied.expired {
+		return len(p), nil
 	}
-	rdr.Close()
-	if sz > 0 {
-		capture <- buf.String()
+	return cg.dest.Write(p)
+}
+
+// delivers the output copied from the pipe once drained or, when 'expire'
+// isn't nil, once it expires, whichever comes first.
+func wfileCapture(rdr io.ReadCloser, buf *captureBuffer, capture chan<- string, copied *captureCopy, expire Timer) {
+	defer close(capture)
+	go func() {
+		_, err := io.Copy(captureGate{copied: copied, dest: buf}, rdr)
+		if err != nil {
+			copied.err = fmt.Errorf("mckio: capture copy failed: %w", err)
+			// continue draining the pipe, so writers to it don't block.
+			io.Copy(io.Discard, rdr)
+		}
+		rdr.Close()
+		// report the outcome before delivering output, as the caller may
+		// wait for it before receiving.
+		close(copied.done)
+	}()
+	var expired <-chan time.Time
+	if expire != nil {
+		defer expire.Stop()
+		expired = expire.C()
+	}
+	select {
+	case <-copied.done:
+	case <-expired:
+		copied.expire()
+	}
+	out := buf.String()
+	if out == "" {
+		return
+	}
+	if buf.delim == nil {
+		capture <- out
+		return
+	}
+	for _, rec := range splitRecords(out, string(buf.delim)) {
+		capture <- rec
 	}
 }
-func cvrtToStringChan(in <-chan interface{}, outstr chan<- string) {
-	defer close(outstr)
-	for o := range in {
-		outstr <- o.(string)
+
+// accumulates captured output, unless streamed to a destination, subject to
+// a memory guard.  once the guard's limit is exceeded, output continues to
+// be drained from the pipe but discarded, so the program writing to it
+// doesn't block.
+type captureBuffer struct {
+	buf   bytes.Buffer
+	guard memGuard
+	tee   io.Writer
+	limit *captureLimit
+	dest  io.Writer
+	onWrt func(p []byte)
+	strip *ansiStrip
+	raw   *bytes.Buffer
+	delim []byte
+}
+
+func newCaptureBuffer(behavior interface{}, orig *os.File) *captureBuffer {
+	cb := &captureBuffer{guard: newMemGuard(behavior)}
+	if bcl, ok := behavior.(BehaviorCaptureLimiter); ok {
+		if head, tail := bcl.BehaviorCaptureLimit(); head > 0 || tail > 0 {
+			cb.limit = newCaptureLimit(head, tail)
+		}
+	}
+	if bd, ok := behavior.(BehaviorDelimer); ok && len(bd.BehaviorDelim()) > 0 {
+		cb.delim = bd.BehaviorDelim()
+	}
+	if bow, ok := behavior.(BehaviorOnWriter); ok {
+		cb.onWrt = bow.BehaviorOnWrite
+	}
+	if bcw, ok := behavior.(BehaviorCaptureDester); ok {
+		cb.dest = bcw.BehaviorCaptureDest()
+	}
+	if bct, ok := behavior.(BehaviorCaptureTeer); ok && bct.BehaviorCaptureTee() && orig != nil {
+		cb.tee = orig
 	}
+	if bas, ok := behavior.(BehaviorCaptureAnsiStripper); ok && bas.BehaviorCaptureAnsiStrip() {
+		cb.strip = &ansiStrip{}
+		if bkr, ok := behavior.(BehaviorKeepRawer); ok && bkr.BehaviorKeepRaw() {
+			cb.raw = &bytes.Buffer{}
+		}
+	}
+	return cb
+}
+func (cb *captureBuffer) Write(p []byte) (int, error) {
+	if _, err := cb.store(cb.filter(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
 }
 
-type stdin struct{}
+// forwards the raw output then returns the text remaining to be stored.
+func (cb *captureBuffer) filter(p []byte) []byte {
+	if cb.tee != nil {
+		// forwarding is best effort, as it mustn't disrupt capturing.
+		cb.tee.Write(p)
+	}
+	if cb.strip == nil {
+		return p
+	}
+	if cb.raw != nil {
+		cb.raw.Write(p)
+	}
+	return cb.strip.apply(p)
+}
+func (cb *captureBuffer) store(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if cb.onWrt != nil {
+		cb.onWrt(p)
+	}
+	if cb.dest != nil {
+		return cb.dest.Write(p)
+	}
+	if cb.limit != nil {
+		return cb.limit.Write(p)
+	}
+	if cb.guard.exceeds(cb.buf.Len() + len(p)) {
+		if room := cb.guard.limit - cb.buf.Len(); room > 0 {
+			cb.buf.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return cb.buf.Write(p)
+}
+func (cb *captureBuffer) String() string {
+	if cb.limit != nil {
+		return cb.limit.String()
+	}
+	return cb.buf.String()
+}
+func (cb *captureBuffer) rawString() string {
+	if cb.raw == nil {
+		return ""
+	}
+	return cb.raw.String()
+}
+func (cb *captureBuffer) discarded() int64 {
+	if cb.limit != nil {
+		return cb.limit.discarded
+	}
+	return 0
+}
+
+// splits out into the records terminated by delim, excluding delim.
+func splitRecords(out string, delim string) []string {
+	recs := strings.SplitAfter(out, delim)
+	if recs[len(recs)-1] == "" {
+		recs = recs[:len(recs)-1]
+	}
+	for i, rec := range recs {
+		recs[i] = strings.TrimSuffix(rec, delim)
+	}
+	return recs
+}
+
+type stdin struct {
+	clock Clock
+}
 
 func (stdin) BehaviorDelim() (delim []byte) {
 	delim = []byte{'\n'}
@@ -341,6 +1489,6 @@ func (stdin) BehaviorDelim() (delim []byte) {
 func (stdin) BehaviorBlockAtEnd() {
 	select {}
 }
-func (stdin) BehaviorBlockBeforeEachRead() {
-	time.Sleep(1 * time.Second)
+func (s stdin) BehaviorBlockBeforeEachRead() {
+	s.clock.Sleep(1 * time.Second)
 }
@@ -1,10 +1,13 @@
 package mckio
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -207,3 +210,678 @@ func Test_FileCaptureStdoutSimple(t *testing.T) {
 	<-output
 	assrt.Equal(stdMsg, cap)
 }
+func Test_FileCaptureStartErr(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	output, captureEnd, err := FileCaptureStartErr(&captFile, nil)
+	assrt.Nil(err)
+	fmt.Fprint(captFile, "line 1\n")
+	assrt.Nil(captureEnd())
+	assrt.Nil(captFile)
+	assrt.Equal("line 1\n", <-output)
+}
+func Test_FileCaptureCopyErr(t *testing.T) {
+	assrt := assert.New(t)
+	rdr, wrt, err := os.Pipe()
+	assrt.Nil(err)
+	errWrite := errors.New("write failed")
+	sink := NewWerr(nil, FailAfterCalls{Err: errWrite})
+	capture := make(chan string, 1)
+	copied := &captureCopy{done: make(chan struct{})}
+	go wfileCapture(rdr, newCaptureBuffer(captureDest{sink}, nil), capture, copied, nil)
+	fmt.Fprint(wrt, "line 1\n")
+	wrt.Close()
+	<-copied.done
+	assrt.True(errors.Is(copied.err, errWrite))
+}
+func Test_FileCaptureDelim(t *testing.T) {
+	assrt := assert.New(t)
+	var captFile *os.File
+	output, captureEnd, err := FileCaptureStartBehavior(&captFile, wsDelim("\n---\n"))
+	assrt.Nil(err)
+	fmt.Fprint(captFile, "blob 1\n---\nblob\n2\n---\nblob 3")
+	captureEnd()
+	var recs []string
+	for rec := range output {
+		recs = append(recs, rec)
+	}
+	assrt.Equal([]string{"blob 1", "blob\n2", "blob 3"}, recs)
+}
+func Test_RstringsScriptEOF(t *testing.T) {
+	assrt := assert.New(t)
+	rdr := NewRstrings([]string{"first", ScriptEOF, "second"}, delimAdd{})
+	assrt.Equal(int64(13), rdr.Size())
+	p := make([]byte, 32)
+	n, err := rdr.Read(p)
+	assrt.Nil(err)
+	assrt.Equal("first\n", string(p[:n]))
+	n, err = rdr.Read(p)
+	assrt.Equal(0, n)
+	assrt.Equal(io.EOF, err)
+	// reading resumes after end of file
+	n, err = rdr.Read(p)
+	assrt.Nil(err)
+	assrt.Equal("second\n", string(p[:n]))
+	_, err = rdr.Read(p)
+	assrt.Equal(io.EOF, err)
+	n, _ = rdr.ReadAt(p, 0)
+	assrt.Equal("first\nsecond\n", string(p[:n]))
+}
+
+type echoTo struct {
+	io.Writer
+}
+
+func (et echoTo) BehaviorEcho() io.Writer {
+	return et.Writer
+}
+func Test_RstringsEcho(t *testing.T) {
+	assrt := assert.New(t)
+	screen := NewWrecorder()
+	in := NewRstrings([]string{"alice", "bob"}, struct {
+		delimAdd
+		echoTo
+	}{echoTo: echoTo{screen}})
+	p := make([]byte, 6)
+	fmt.Fprint(screen, "name? ")
+	n, _ := in.Read(p)
+	fmt.Fprintf(screen, "hello %s", p[:n])
+	assrt.Equal("name? alice\nhello alice\n", screen.String())
+	_, err := in.Read(p[:0])
+	assrt.Nil(err)
+	assrt.Equal("name? alice\nhello alice\n", screen.String())
+}
+func Test_RstringsDelimFunc(t *testing.T) {
+	assrt := assert.New(t)
+	cmds := []string{"cmmd 1", "cmmd 2", "cmmd 3"}
+	rdr := NewRstrings(cmds, delimAlternate{})
+	p := make([]byte, 4)
+	var rslt string
+	for {
+		sz, err := rdr.Read(p)
+		rslt += string(p[0:sz])
+		if err != nil {
+			assrt.IsType(io.EOF, err)
+			break
+		}
+	}
+	assrt.Equal("cmmd 1\ncmmd 2\r\ncmmd 3\n", rslt)
+}
+func Test_RstringsDelimFuncSupersedesDelim(t *testing.T) {
+	assrt := assert.New(t)
+	cmds := []string{"cmmd 1", "cmmd 2"}
+	rdr := NewRstrings(cmds, delimBoth{})
+	p := make([]byte, 64)
+	sz, err := rdr.Read(p)
+	assrt.Nil(err)
+	assrt.Equal("cmmd 1cmmd 2|", string(p[0:sz]))
+}
+
+type delimAlternate struct{}
+
+func (delimAlternate) BehaviorDelimFunc(index int, s string) []byte {
+	if index%2 == 0 {
+		return []byte{'\n'}
+	}
+	return []byte{'\r', '\n'}
+}
+
+type delimBoth struct {
+	delimAdd
+}
+
+func (delimBoth) BehaviorDelimFunc(index int, s string) []byte {
+	if index == 0 {
+		return nil
+	}
+	return []byte{'|'}
+}
+func Test_RstringsReadAt(t *testing.T) {
+	assrt := assert.New(t)
+	cmds := []string{"cmmd 1", "cmmd 2", "cmmd 3"}
+	rdr := NewRstrings(cmds, delimAdd{})
+	flat := "cmmd 1\ncmmd 2\ncmmd 3\n"
+	p := make([]byte, 5)
+	sz, err := rdr.ReadAt(p, 4)
+	assrt.Equal(5, sz)
+	assrt.Nil(err)
+	assrt.Equal(flat[4:9], string(p))
+	// independent of sequential read cursor
+	rdr.Read(make([]byte, 10))
+	sz, err = rdr.ReadAt(p, 0)
+	assrt.Equal(flat[0:5], string(p[:sz]))
+	assrt.Nil(err)
+	// request crossing end of region
+	sz, err = rdr.ReadAt(p, int64(len(flat)-2))
+	assrt.Equal(2, sz)
+	assrt.Equal(io.EOF, err)
+	assrt.Equal(flat[len(flat)-2:], string(p[:sz]))
+	sz, err = rdr.ReadAt(p, int64(len(flat)+10))
+	assrt.Zero(sz)
+	assrt.Equal(io.EOF, err)
+	_, err = rdr.ReadAt(p, -1)
+	assrt.NotNil(err)
+}
+func Test_RstringsLenSize(t *testing.T) {
+	assrt := assert.New(t)
+	cmds := []string{"cmmd 1", "cmmd 2", "cmmd 3"}
+	rdr := NewRstrings(cmds, delimAdd{})
+	total := byteSizeCalc(cmds) + len(cmds)
+	assrt.Equal(int64(total), rdr.Size())
+	assrt.Equal(total, rdr.Len())
+	// stop within a string then within a delimiter
+	for _, consume := range []int{3, 4, 1} {
+		p := make([]byte, consume)
+		sz, _ := rdr.Read(p)
+		total -= sz
+		assrt.Equal(total, rdr.Len())
+	}
+	p := make([]byte, rdr.Len())
+	sz, err := rdr.Read(p)
+	assrt.Equal(len(p), sz)
+	assrt.Nil(err)
+	assrt.Zero(rdr.Len())
+	assrt.Equal(int64(byteSizeCalc(cmds)+len(cmds)), rdr.Size())
+}
+func Test_RstringsDebugState(t *testing.T) {
+	assrt := assert.New(t)
+	cmds := []string{"cmmd 1", "cmmd 2"}
+	blk := &blockEnd{reached: make(chan struct{}), release: make(chan struct{})}
+	rdr := NewRstrings(cmds, blk)
+	assrt.Equal(ReaderState{}, rdr.DebugState())
+	p := make([]byte, 8)
+	rdr.Read(p)
+	assrt.Equal(ReaderState{Element: 1, Offset: 2}, rdr.DebugState())
+	assrt.Equal("Rstrings{element: 1 of 2, offset: 2, delim: 0, blocked: false}", rdr.String())
+	rdr.Read(p)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		rdr.Read(p)
+	}()
+	<-blk.reached
+	assrt.True(rdr.DebugState().Blocked)
+	close(blk.release)
+	<-done
+	assrt.False(rdr.DebugState().Blocked)
+}
+func Test_RchanDebugState(t *testing.T) {
+	assrt := assert.New(t)
+	cmdLn := make(chan string)
+	rdr := NewChan(cmdLn)
+	assrt.Equal(ReaderState{Element: -1}, rdr.DebugState())
+	go func() { cmdLn <- "0123456789" }()
+	rdr.Read(make([]byte, 4))
+	assrt.Equal("Rchan{element: 0, offset: 4, delim: 0, blocked: false}", rdr.String())
+}
+
+type blockEnd struct {
+	reached chan struct{}
+	release chan struct{}
+}
+
+func (b *blockEnd) BehaviorBlockAtEnd() {
+	close(b.reached)
+	<-b.release
+}
+func Test_RchanPushUnbuffered(t *testing.T) {
+	assrt := assert.New(t)
+	rdr, push, end := NewChanPush(0)
+	pushed := make(chan struct{})
+	go func() {
+		defer end()
+		push("cmmd 1")
+		close(pushed)
+	}()
+	select {
+	case <-pushed:
+		assrt.Fail("push should block until the reader receives")
+	case <-time.After(10 * time.Millisecond):
+	}
+	p := make([]byte, 16)
+	sz, err := rdr.Read(p)
+	assrt.Equal("cmmd 1", string(p[:sz]))
+	assrt.Nil(err)
+	<-pushed
+	sz, err = rdr.Read(p)
+	assrt.Zero(sz)
+	assrt.IsType(io.EOF, err)
+	// end is idempotent
+	end()
+}
+func Test_RchanPushBuffered(t *testing.T) {
+	assrt := assert.New(t)
+	rdr, push, end := NewChanPush(2)
+	// shouldn't block as the buffer accepts both
+	push("cmmd 1")
+	push("cmmd 2")
+	end()
+	p := make([]byte, 16)
+	var rslt string
+	for {
+		sz, err := rdr.Read(p)
+		rslt += string(p[:sz])
+		if err != nil {
+			break
+		}
+	}
+	assrt.Equal("cmmd 1cmmd 2", rslt)
+}
+func Test_RchanContextCancel(t *testing.T) {
+	assrt := assert.New(t)
+	cmdLn := make(chan string)
+	ctx, cancel := context.WithCancel(context.Background())
+	rdr := NewChanBehavior(cmdLn, chanCtx{ctx: ctx})
+	go func() { cmdLn <- "cmmd 1" }()
+	p := make([]byte, 16)
+	sz, err := rdr.Read(p)
+	assrt.Equal("cmmd 1", string(p[:sz]))
+	assrt.Nil(err)
+	// producer stalls
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	sz, err = rdr.Read(p)
+	assrt.Zero(sz)
+	assrt.Equal(context.Canceled, err)
+}
+func Test_RchanContextCancelErr(t *testing.T) {
+	assrt := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	errStalled := errors.New("producer stalled")
+	rdr := NewChanBehavior(make(chan string), chanCtxErr{chanCtx{ctx: ctx}, errStalled})
+	sz, err := rdr.Read(make([]byte, 16))
+	assrt.Zero(sz)
+	assrt.Equal(errStalled, err)
+}
+
+type chanCtx struct {
+	ctx context.Context
+}
+
+func (cc chanCtx) BehaviorContext() context.Context {
+	return cc.ctx
+}
+
+type chanCtxErr struct {
+	chanCtx
+	err error
+}
+
+func (cce chanCtxErr) BehaviorCancelErr() error {
+	return cce.err
+}
+func Test_RchanCloseDrain(t *testing.T) {
+	assrt := assert.New(t)
+	cmdLn := make(chan string, 3)
+	rdr := NewChanBehavior(cmdLn, closeDiscard(false))
+	cmdLn <- "cmmd 1"
+	cmdLn <- "cmmd 2"
+	cmdLn <- "cmmd 3"
+	close(cmdLn)
+	p := make([]byte, 3)
+	var rslt string
+	for {
+		sz, err := rdr.Read(p)
+		rslt += string(p[:sz])
+		if err != nil {
+			assrt.IsType(io.EOF, err)
+			break
+		}
+	}
+	assrt.Equal("cmmd 1cmmd 2cmmd 3", rslt)
+}
+func Test_RchanCloseDiscard(t *testing.T) {
+	assrt := assert.New(t)
+	cmdLn := make(chan string, 3)
+	rdr := NewChanBehavior(cmdLn, closeDiscard(true))
+	cmdLn <- "cmmd 1"
+	p := make([]byte, 3)
+	sz, err := rdr.Read(p)
+	assrt.Equal("cmm", string(p[:sz]))
+	assrt.Nil(err)
+	cmdLn <- "cmmd 2"
+	cmdLn <- "cmmd 3"
+	close(cmdLn)
+	// residual of first message delivered as closure isn't yet observed
+	sz, err = rdr.Read(p)
+	assrt.Equal("d 1", string(p[:sz]))
+	// receiving "cmmd 2" observes closure discarding it and "cmmd 3"
+	sz, err = rdr.Read(p)
+	assrt.Zero(sz)
+	assrt.IsType(io.EOF, err)
+	sz, err = rdr.Read(p)
+	assrt.Zero(sz)
+	assrt.IsType(io.EOF, err)
+}
+
+type closeDiscard bool
+
+func (cd closeDiscard) BehaviorCloseDiscard() bool {
+	return bool(cd)
+}
+func Test_RchanReadDeadline(t *testing.T) {
+	assrt := assert.New(t)
+	cmdLn := make(chan string, 1)
+	rdr := NewChan(cmdLn)
+	rdr.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	p := make([]byte, 16)
+	sz, err := rdr.Read(p)
+	assrt.Zero(sz)
+	assrt.Equal(os.ErrDeadlineExceeded, err)
+	// deadline in the past times out immediately
+	rdr.SetReadDeadline(time.Now().Add(-time.Second))
+	_, err = rdr.Read(p)
+	assrt.Equal(os.ErrDeadlineExceeded, err)
+	// clearing deadline allows delivery
+	rdr.SetReadDeadline(time.Time{})
+	cmdLn <- "cmmd 1"
+	sz, err = rdr.Read(p)
+	assrt.Equal("cmmd 1", string(p[:sz]))
+	assrt.Nil(err)
+}
+func Test_RchanReadDeadlineExtendedWhileBlocked(t *testing.T) {
+	assrt := assert.New(t)
+	cmdLn := make(chan string)
+	rdr := NewChan(cmdLn)
+	rdr.SetReadDeadline(time.Now().Add(20 * time.Millisecond))
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		rdr.SetReadDeadline(time.Time{})
+		time.Sleep(30 * time.Millisecond)
+		cmdLn <- "late"
+	}()
+	p := make([]byte, 16)
+	sz, err := rdr.Read(p)
+	assrt.Equal("late", string(p[:sz]))
+	assrt.Nil(err)
+}
+func Test_RchanWithErrs(t *testing.T) {
+	assrt := assert.New(t)
+	data := make(chan string)
+	errs := make(chan error)
+	rdr := NewChanWithErrs(data, errs)
+	errTransient := errors.New("transient")
+	go func() {
+		defer close(data)
+		data <- "cmmd 1"
+		errs <- errTransient
+		close(errs)
+		data <- "cmmd 2"
+	}()
+	p := make([]byte, 4)
+	sz, err := rdr.Read(p)
+	assrt.Equal("cmmd", string(p[:sz]))
+	assrt.Nil(err)
+	// residual bytes precede the error
+	sz, err = rdr.Read(p)
+	assrt.Equal(" 1", string(p[:sz]))
+	assrt.Nil(err)
+	sz, err = rdr.Read(p)
+	assrt.Zero(sz)
+	assrt.Equal(errTransient, err)
+	// stream continues after transient error
+	all, err := io.ReadAll(&rdr)
+	assrt.Nil(err)
+	assrt.Equal("cmmd 2", string(all))
+}
+func Test_RchanDelim(t *testing.T) {
+	assrt := assert.New(t)
+	cmdLn := make(chan string, 3)
+	rdr := NewChanBehavior(cmdLn, delimAdd{})
+	cmdLn <- "cmmd 1"
+	cmdLn <- ""
+	cmdLn <- "cmmd 2"
+	close(cmdLn)
+	all, err := io.ReadAll(&rdr)
+	assrt.Nil(err)
+	assrt.Equal("cmmd 1\n\ncmmd 2\n", string(all))
+}
+func Test_RchanDelimFuncSegmentAcrossCalls(t *testing.T) {
+	assrt := assert.New(t)
+	cmdLn := make(chan string, 2)
+	rdr := NewChanBehavior(cmdLn, delimAlternate{})
+	cmdLn <- "cmmd 1"
+	cmdLn <- "cmmd 2"
+	close(cmdLn)
+	p := make([]byte, 7)
+	sz, _ := rdr.Read(p)
+	assrt.Equal("cmmd 1\n", string(p[:sz]))
+	sz, _ = rdr.Read(p)
+	assrt.Equal("cmmd 2\r", string(p[:sz]))
+	assrt.Equal(1, rdr.DebugState().Delim)
+	sz, _ = rdr.Read(p)
+	assrt.Equal("\n", string(p[:sz]))
+}
+func Test_RchanNonBlockDrain(t *testing.T) {
+	assrt := assert.New(t)
+	cmdLn := make(chan string, 3)
+	rdr := NewChanBehavior(cmdLn, nonBlock(true))
+	p := make([]byte, 16)
+	// empty channel returns immediately
+	sz, err := rdr.Read(p)
+	assrt.Zero(sz)
+	assrt.Nil(err)
+	cmdLn <- "cmmd 1"
+	cmdLn <- "cmmd 2"
+	cmdLn <- "cmmd 3"
+	sz, err = rdr.Read(p)
+	assrt.Equal("cmmd 1cmmd 2cmmd", string(p[:sz]))
+	assrt.Nil(err)
+	close(cmdLn)
+	sz, err = rdr.Read(p)
+	assrt.Equal(" 3", string(p[:sz]))
+	assrt.Nil(err)
+	sz, err = rdr.Read(p)
+	assrt.Zero(sz)
+	assrt.IsType(io.EOF, err)
+}
+func Test_RchanNonBlockPendingErr(t *testing.T) {
+	assrt := assert.New(t)
+	data := make(chan string, 1)
+	errs := make(chan error, 1)
+	rdr := NewChanWithErrs(data, errs)
+	rdr.nonBlock = true
+	data <- "cmmd 1"
+	p := make([]byte, 16)
+	sz, err := rdr.Read(p)
+	assrt.Equal("cmmd 1", string(p[:sz]))
+	errInject := errors.New("inject")
+	errs <- errInject
+	sz, err = rdr.Read(p)
+	assrt.Zero(sz)
+	assrt.Equal(errInject, err)
+}
+
+type nonBlock bool
+
+func (nb nonBlock) BehaviorNonBlock() bool {
+	return bool(nb)
+}
+func Test_RchanCloseBlockedRead(t *testing.T) {
+	assrt := assert.New(t)
+	cmdLn := make(chan string)
+	rdr := NewChan(cmdLn)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		rdr.Close()
+	}()
+	p := make([]byte, 16)
+	sz, err := rdr.Read(p)
+	assrt.Zero(sz)
+	assrt.Equal(os.ErrClosed, err)
+	// future reads fail and channel remains open for other consumers
+	_, err = rdr.Read(p)
+	assrt.Equal(os.ErrClosed, err)
+	assrt.Nil(rdr.Close())
+	go func() { cmdLn <- "shared" }()
+	assrt.Equal("shared", <-cmdLn)
+}
+func Test_RchanCloseErr(t *testing.T) {
+	assrt := assert.New(t)
+	errAbort := errors.New("test over")
+	cmdLn := make(chan string, 1)
+	rdr := NewChanBehavior(cmdLn, closeErr{errAbort})
+	cmdLn <- "cmmd 1"
+	rdr.Close()
+	sz, err := rdr.Read(make([]byte, 16))
+	assrt.Zero(sz)
+	assrt.Equal(errAbort, err)
+}
+
+type closeErr struct {
+	err error
+}
+
+func (ce closeErr) BehaviorCloseErr() error {
+	return ce.err
+}
+func Test_RchanChunkSize(t *testing.T) {
+	assrt := assert.New(t)
+	cmdLn := make(chan string, 2)
+	rdr := NewChanBehavior(cmdLn, chunkSize(4))
+	cmdLn <- "0123456789"
+	cmdLn <- "ab"
+	close(cmdLn)
+	p := make([]byte, 64)
+	var chunks []string
+	for {
+		sz, err := rdr.Read(p)
+		if err != nil {
+			break
+		}
+		chunks = append(chunks, string(p[:sz]))
+	}
+	assrt.Equal([]string{"0123", "4567", "89", "ab"}, chunks)
+}
+
+type chunkSize int
+
+func (cs chunkSize) BehaviorChunkSize() int {
+	return int(cs)
+}
+func Test_RchanBlockAtEnd(t *testing.T) {
+	assrt := assert.New(t)
+	cmdLn := make(chan string, 1)
+	blk := &blockEnd{reached: make(chan struct{}), release: make(chan struct{})}
+	rdr := NewChanBehavior(cmdLn, blk)
+	cmdLn <- "cmmd 1"
+	close(cmdLn)
+	p := make([]byte, 16)
+	sz, err := rdr.Read(p)
+	assrt.Equal("cmmd 1", string(p[:sz]))
+	assrt.Nil(err)
+	done := make(chan error)
+	go func() {
+		_, err := rdr.Read(p)
+		done <- err
+	}()
+	<-blk.reached
+	assrt.True(rdr.DebugState().Blocked)
+	close(blk.release)
+	assrt.IsType(io.EOF, <-done)
+	assrt.False(rdr.DebugState().Blocked)
+}
+func Test_RchanBlockBeforeEachRead(t *testing.T) {
+	assrt := assert.New(t)
+	cmdLn := make(chan string, 2)
+	cmdLn <- "cmmd 1"
+	cmdLn <- "cmmd 2"
+	close(cmdLn)
+	bb := &blockBefore{gate: make(chan struct{})}
+	rdr := NewChanBehavior(cmdLn, bb)
+	p := make([]byte, 16)
+	rslt := make(chan string)
+	go func() {
+		for {
+			sz, err := rdr.Read(p)
+			if err != nil {
+				close(rslt)
+				return
+			}
+			rslt <- string(p[:sz])
+		}
+	}()
+	select {
+	case <-rslt:
+		assrt.Fail("read should wait until released")
+	case <-time.After(10 * time.Millisecond):
+	}
+	bb.gate <- struct{}{}
+	assrt.Equal("cmmd 1", <-rslt)
+	bb.gate <- struct{}{}
+	assrt.Equal("cmmd 2", <-rslt)
+	bb.gate <- struct{}{}
+	_, open := <-rslt
+	assrt.False(open)
+}
+
+type blockBefore struct {
+	gate chan struct{}
+}
+
+func (b *blockBefore) BehaviorBlockBeforeEachRead() {
+	<-b.gate
+}
+func Test_RchanStats(t *testing.T) {
+	assrt := assert.New(t)
+	cmdLn := make(chan string, 2)
+	cmdLn <- "cmmd 1"
+	cmdLn <- "cmmd 22"
+	close(cmdLn)
+	rdr := NewChanBehavior(cmdLn, &delimAdd{})
+	assrt.Equal(RchanStats{}, rdr.Stats())
+	_, err := io.ReadAll(&rdr)
+	assrt.Nil(err)
+	assrt.Equal(RchanStats{Messages: 2, Bytes: 15}, rdr.Stats())
+}
+func Test_RchanWaitNotify(t *testing.T) {
+	assrt := assert.New(t)
+	cmdLn := make(chan string)
+	wn := waitNotify(make(chan struct{}, 1))
+	rdr := NewChanBehavior(cmdLn, wn)
+	rslt := make(chan string)
+	go func() {
+		p := make([]byte, 16)
+		for {
+			sz, err := rdr.Read(p)
+			if err != nil {
+				close(rslt)
+				return
+			}
+			rslt <- string(p[:sz])
+		}
+	}()
+	for _, msg := range []string{"cmmd 1", "cmmd 2"} {
+		<-wn
+		cmdLn <- msg
+		assrt.Equal(msg, <-rslt)
+	}
+	<-wn
+	close(cmdLn)
+	_, open := <-rslt
+	assrt.False(open)
+}
+
+type waitNotify chan struct{}
+
+func (wn waitNotify) BehaviorWaitNotify() chan<- struct{} {
+	return wn
+}
+func Benchmark_RchanLargeMessages(b *testing.B) {
+	msg := string(make([]byte, 64*1024))
+	cmdLn := make(chan string, 1)
+	rdr := NewChan(cmdLn)
+	p := make([]byte, 4096)
+	b.SetBytes(int64(len(msg)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		cmdLn <- msg
+		for rem := len(msg); rem > 0; {
+			sz, _ := rdr.Read(p)
+			rem -= sz
+		}
+	}
+}
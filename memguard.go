@@ -0,0 +1,76 @@
+package mckio
+
+import (
+	"errors"
+	"testing"
+)
+
+/*
+BehaviorMemoryGuarder limits the number of bytes a mock buffers internally.
+Once its buffering would exceed 'limit' bytes, the mock calls 'exceeded'
+with the number of bytes it would have buffered.  'exceeded' is called at
+most once per mock and may be called from a goroutine other than the test's.
+
+This catches consumers that stop draining a stream, which would exhaust
+memory in production, instead of the mock silently absorbing the stream.
+*/
+type BehaviorMemoryGuarder interface {
+	BehaviorMemoryGuard() (limit int, exceeded func(buffered int))
+}
+
+/*
+MemoryGuard implements BehaviorMemoryGuarder by failing the test once a
+mock's buffering exceeds Limit bytes.  Embed it in a behavior struct to
+combine it with other behaviors.
+*/
+type MemoryGuard struct {
+	TB    testing.TB
+	Limit int
+}
+
+/*
+ErrMemoryGuard is returned by writes rejected by a mock whose buffering
+would exceed the limit specified by BehaviorMemoryGuarder.
+*/
+var ErrMemoryGuard = errors.New("mckio: memory guard limit exceeded")
+
+/*
+BehaviorMemoryGuard fails the test using testing.TB.Errorf, as it's safe to
+call from any goroutine.
+*/
+func (mg MemoryGuard) BehaviorMemoryGuard() (limit int, exceeded func(buffered int)) {
+	return mg.Limit, func(buffered int) {
+		mg.TB.Helper()
+		mg.TB.Errorf("mckio: buffering %d bytes exceeds memory guard limit of %d bytes - consumer may have stopped draining", buffered, mg.Limit)
+	}
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+type memGuard struct {
+	limit    int
+	exceeded func(buffered int)
+	tripped  bool
+}
+
+func newMemGuard(behavior interface{}) (mg memGuard) {
+	if gd, ok := behavior.(BehaviorMemoryGuarder); ok {
+		mg.limit, mg.exceeded = gd.BehaviorMemoryGuard()
+	}
+	return mg
+}
+
+// reports if buffering the given number of bytes violates the guard.
+// notification of the violation happens only once.
+func (mg *memGuard) exceeds(buffered int) bool {
+	if mg.limit < 1 || buffered <= mg.limit {
+		return false
+	}
+	if !mg.tripped {
+		mg.tripped = true
+		mg.exceeded(buffered)
+	}
+	return true
+}
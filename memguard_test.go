@@ -0,0 +1,65 @@
+package mckio
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MemoryGuardFilter(t *testing.T) {
+	assrt := assert.New(t)
+	guard := &guardRecord{limit: 8}
+	f := NewFilter(nil, guard)
+	_, err := f.Write([]byte("01234567"))
+	assrt.Nil(err)
+	assrt.Zero(guard.calls)
+	_, err = f.Write([]byte("8"))
+	assrt.Equal(ErrMemoryGuard, err)
+	_, err = f.Write([]byte("9"))
+	assrt.Equal(ErrMemoryGuard, err)
+	assrt.Equal(1, guard.calls)
+	assrt.Equal(9, guard.buffered)
+}
+func Test_MemoryGuardFileCapture(t *testing.T) {
+	assrt := assert.New(t)
+	guard := &guardRecord{limit: 4}
+	output, captureEnd, err := FileCaptureStartBehavior(&os.Stdout, guard)
+	assrt.Nil(err)
+	fmt.Print("0123456789")
+	captureEnd()
+	assrt.Equal("0123", <-output)
+	assrt.Equal(1, guard.calls)
+}
+func Test_MemoryGuardFailsTest(t *testing.T) {
+	assrt := assert.New(t)
+	tb := &tbRecord{TB: t}
+	f := NewFilter(nil, MemoryGuard{TB: tb, Limit: 2})
+	f.Write([]byte("012"))
+	assrt.Len(tb.errs, 1)
+}
+
+type guardRecord struct {
+	limit    int
+	calls    int
+	buffered int
+}
+
+func (gr *guardRecord) BehaviorMemoryGuard() (int, func(int)) {
+	return gr.limit, func(buffered int) {
+		gr.calls++
+		gr.buffered = buffered
+	}
+}
+
+// records failures instead of failing the test running it.
+type tbRecord struct {
+	testing.TB
+	errs []string
+}
+
+func (tr *tbRecord) Helper() {}
+func (tr *tbRecord) Errorf(format string, args ...interface{}) {
+	tr.errs = append(tr.errs, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,156 @@
+package mckio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+/*
+PromptCheck detects prompts, output lines lacking a trailing newline, that
+remain buffered by the program instead of being written before it reads
+its input.  This catches the classic "prompt appears only after input" bug
+caused by writing prompts through an unflushed buffer.
+
+It wraps the reader supplying stdin and the writer receiving stdout.  Before
+each read that begins a new line of input, the prompt must appear as the
+tail of the output within a threshold duration.  The prompt must also be
+written by a single Write, as a prompt assembled by several writes can
+render partially on a terminal.
+
+- Input read ahead of the current line, like several lines returned by a
+single Read of the wrapped reader, is buffered, so every line's prompt is
+checked.
+
+- Violations are recorded instead of failing the read, so the program
+continues to run.  Err reports the first one.
+
+- PromptCheck is concurrency safe.
+*/
+type PromptCheck struct {
+	lines     lineGate
+	mu        sync.Mutex
+	out       io.Writer
+	prompt    []byte
+	threshold time.Duration
+	tail      []byte
+	prompted  bool
+	wrote     chan struct{}
+	errs      []error
+}
+
+/*
+NewPromptCheck wraps 'in' and 'out' verifying 'prompt' is written to 'out'
+no later than 'threshold' after the program attempts to read a line from
+'in'.  Provide the values returned by Reader and Writer to the code under
+test.
+*/
+func NewPromptCheck(prompt string, in io.Reader, out io.Writer, threshold time.Duration) *PromptCheck {
+	return &PromptCheck{
+		lines:     lineGate{in: in},
+		out:       out,
+		prompt:    []byte(prompt),
+		threshold: threshold,
+		wrote:     make(chan struct{}, 1),
+	}
+}
+
+/*
+Reader returns an io.Reader that verifies the prompt was written before
+reading each line from the wrapped reader.
+*/
+func (pc *PromptCheck) Reader() io.Reader {
+	return promptReader{pc}
+}
+
+/*
+Writer returns an io.Writer that observes the prompt written to the wrapped
+writer.
+*/
+func (pc *PromptCheck) Writer() io.Writer {
+	return promptWriter{pc}
+}
+
+/*
+Err returns the first violation detected or nil.
+*/
+func (pc *PromptCheck) Err() error {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	if len(pc.errs) < 1 {
+		return nil
+	}
+	return pc.errs[0]
+}
+
+/*
+Violations returns every violation detected.
+*/
+func (pc *PromptCheck) Violations() []error {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	return append([]error(nil), pc.errs...)
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+type promptReader struct {
+	pc *PromptCheck
+}
+
+func (pr promptReader) Read(p []byte) (int, error) {
+	return pr.pc.lines.read(p, pr.pc.awaitPrompt)
+}
+
+// records a violation unless the prompt appears within the threshold.  it
+// never fails the read.
+func (pc *PromptCheck) awaitPrompt() error {
+	expire := time.After(pc.threshold)
+	for {
+		pc.mu.Lock()
+		if pc.prompted {
+			pc.prompted = false
+			pc.mu.Unlock()
+			return nil
+		}
+		pc.mu.Unlock()
+		select {
+		case <-pc.wrote:
+		case <-expire:
+			pc.mu.Lock()
+			defer pc.mu.Unlock()
+			pc.errs = append(pc.errs, fmt.Errorf("mckio: prompt %q not written within %v of reading input - output ends with \"%s\"", pc.prompt, pc.threshold, excerpt(string(pc.tail))))
+			return nil
+		}
+	}
+}
+
+type promptWriter struct {
+	pc *PromptCheck
+}
+
+func (pw promptWriter) Write(p []byte) (int, error) {
+	pc := pw.pc
+	n, err := pc.out.Write(p)
+	pc.mu.Lock()
+	pc.tail = append(pc.tail, p[:n]...)
+	if i := bytes.LastIndexByte(pc.tail, '\n'); i > -1 {
+		pc.tail = pc.tail[i+1:]
+	}
+	if len(pc.prompt) > 0 && bytes.HasSuffix(pc.tail, pc.prompt) {
+		pc.prompted = true
+		if !bytes.Contains(p[:n], pc.prompt) {
+			pc.errs = append(pc.errs, fmt.Errorf("mckio: prompt %q split across several writes", pc.prompt))
+		}
+	}
+	pc.mu.Unlock()
+	select {
+	case pc.wrote <- struct{}{}:
+	default:
+	}
+	return n, err
+}
@@ -0,0 +1,72 @@
+package mckio
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PromptCheckFlushed(t *testing.T) {
+	assrt := assert.New(t)
+	in := NewRstrings([]string{"cmmd 1", "cmmd 2"}, delimAdd{})
+	var out bytes.Buffer
+	pc := NewPromptCheck("> ", &in, &out, 50*time.Millisecond)
+	promptLoop(pc.Reader(), pc.Writer(), true)
+	assrt.Nil(pc.Err())
+	assrt.Equal("> cmmd 1\n> cmmd 2\n> ", out.String())
+}
+func Test_PromptCheckUnflushed(t *testing.T) {
+	assrt := assert.New(t)
+	in := NewRstrings([]string{"cmmd 1"}, delimAdd{})
+	var out bytes.Buffer
+	pc := NewPromptCheck("> ", &in, &out, 10*time.Millisecond)
+	promptLoop(pc.Reader(), pc.Writer(), false)
+	assrt.NotNil(pc.Err())
+}
+func Test_PromptCheckSplitWrite(t *testing.T) {
+	assrt := assert.New(t)
+	in := NewRstrings([]string{"cmmd 1"}, delimAdd{})
+	var out bytes.Buffer
+	pc := NewPromptCheck("> ", &in, &out, 10*time.Millisecond)
+	pc.Writer().Write([]byte(">"))
+	pc.Writer().Write([]byte(" "))
+	bufio.NewReader(pc.Reader()).ReadString('\n')
+	assrt.Len(pc.Violations(), 1)
+}
+func Test_PromptCheckLinesPerRead(t *testing.T) {
+	assrt := assert.New(t)
+	in := strings.NewReader("cmmd 1\ncmmd 2\n")
+	var out bytes.Buffer
+	pc := NewPromptCheck("> ", in, &out, 10*time.Millisecond)
+	pc.Writer().Write([]byte("> "))
+	p := make([]byte, 32)
+	n, _ := pc.Reader().Read(p)
+	assrt.Equal("cmmd 1\n", string(p[:n]))
+	assrt.Nil(pc.Err())
+	// the second line, already read from 'in', still awaits its prompt.
+	n, _ = pc.Reader().Read(p)
+	assrt.Equal("cmmd 2\n", string(p[:n]))
+	assrt.Len(pc.Violations(), 1)
+}
+func promptLoop(in io.Reader, out io.Writer, flush bool) {
+	bout := bufio.NewWriter(out)
+	defer bout.Flush()
+	rdr := bufio.NewReader(in)
+	for {
+		fmt.Fprint(bout, "> ")
+		if flush {
+			bout.Flush()
+		}
+		ln, err := rdr.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fmt.Fprint(bout, ln)
+	}
+}
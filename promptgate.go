@@ -0,0 +1,189 @@
+package mckio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+/*
+PromptGate coordinates a request/response dialog with the code under test,
+withholding each line of input until the program prompts for it, so tests
+neither sleep nor race the program's printing of its prompt.
+
+It wraps the reader supplying stdin and the writer receiving stdout.  A Read
+beginning a new line of input blocks until the output written since the
+previous line satisfies the prompt's WriteMatcher, like ExpectContains or
+ExpectRegexp.
+
+- Input read ahead of the current line, like several lines returned by a
+single Read of the wrapped reader, is buffered, so every line waits for its
+own prompt.
+
+- When the prompt doesn't appear within the timeout, the Read fails with an
+error wrapping os.ErrDeadlineExceeded, as does every subsequent Read.  Err
+reports it too.
+
+- PromptGate is concurrency safe.
+*/
+type PromptGate struct {
+	lines   lineGate
+	mu      sync.Mutex
+	out     io.Writer
+	prompt  WriteMatcher
+	timeout time.Duration
+	pending []byte
+	changed chan struct{}
+	err     error
+}
+
+/*
+NewPromptGate wraps 'in' and 'out', withholding each line read from 'in'
+until output written to 'out' satisfies 'prompt'.  A nil 'out' discards the
+output.  Provide the values returned by Reader and Writer to the code under
+test.
+*/
+func NewPromptGate(prompt WriteMatcher, in io.Reader, out io.Writer, timeout time.Duration) *PromptGate {
+	if out == nil {
+		out = io.Discard
+	}
+	return &PromptGate{
+		lines:   lineGate{in: in},
+		out:     out,
+		prompt:  prompt,
+		timeout: timeout,
+		changed: make(chan struct{}),
+	}
+}
+
+/*
+Reader returns an io.Reader that waits for the prompt before reading each
+line from the wrapped reader.
+*/
+func (pg *PromptGate) Reader() io.Reader {
+	return gateReader{pg}
+}
+
+/*
+Writer returns an io.Writer that observes the output written to the wrapped
+writer.
+*/
+func (pg *PromptGate) Writer() io.Writer {
+	return gateWriter{pg}
+}
+
+/*
+Err returns the error reported when a prompt failed to appear or nil.
+*/
+func (pg *PromptGate) Err() error {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+	return pg.err
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+type gateReader struct {
+	pg *PromptGate
+}
+
+func (gr gateReader) Read(p []byte) (int, error) {
+	return gr.pg.lines.read(p, gr.pg.awaitPrompt)
+}
+
+// delivers the input read from 'in' at most a line per Read, so 'before'
+// executes ahead of each line, even when a single Read of 'in' returns
+// several lines.  input read ahead of the current line is buffered.
+type lineGate struct {
+	mu      sync.Mutex
+	in      io.Reader
+	ahead   []byte
+	inErr   error
+	midLine bool
+}
+
+func (lg *lineGate) read(p []byte, before func() error) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	lg.mu.Lock()
+	defer lg.mu.Unlock()
+	if !lg.midLine {
+		if err := before(); err != nil {
+			return 0, err
+		}
+	}
+	if len(lg.ahead) == 0 {
+		if lg.inErr != nil {
+			return 0, lg.inErr
+		}
+		buf := make([]byte, len(p))
+		n, err := lg.in.Read(buf)
+		lg.ahead, lg.inErr = buf[:n], err
+		if n == 0 {
+			return 0, err
+		}
+	}
+	// deliver at most the remainder of the current line.
+	line := lg.ahead
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i+1]
+	}
+	n := copy(p, line)
+	lg.ahead = lg.ahead[n:]
+	lg.midLine = p[n-1] != '\n'
+	return n, nil
+}
+
+// waits for the prompt preceding a new line of input.
+func (pg *PromptGate) awaitPrompt() error {
+	var expire <-chan time.Time
+	for {
+		pg.mu.Lock()
+		if pg.err != nil {
+			defer pg.mu.Unlock()
+			return pg.err
+		}
+		if pg.prompt.MatchWrite(pg.pending) {
+			// the next line's prompt must be written anew.
+			pg.pending = nil
+			pg.mu.Unlock()
+			return nil
+		}
+		changed := pg.changed
+		pg.mu.Unlock()
+		if expire == nil {
+			tmr := time.NewTimer(pg.timeout)
+			defer tmr.Stop()
+			expire = tmr.C
+		}
+		select {
+		case <-changed:
+		case <-expire:
+			pg.mu.Lock()
+			defer pg.mu.Unlock()
+			pg.err = fmt.Errorf("mckio: prompt %s not written within %v - output \"%s\": %w", pg.prompt, pg.timeout, excerpt(string(pg.pending)), os.ErrDeadlineExceeded)
+			return pg.err
+		}
+	}
+}
+
+type gateWriter struct {
+	pg *PromptGate
+}
+
+func (gw gateWriter) Write(p []byte) (int, error) {
+	pg := gw.pg
+	pg.mu.Lock()
+	pg.pending = append(pg.pending, p...)
+	// wake the reader so it reevaluates the prompt.
+	close(pg.changed)
+	pg.changed = make(chan struct{})
+	pg.mu.Unlock()
+	return pg.out.Write(p)
+}
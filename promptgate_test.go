@@ -0,0 +1,79 @@
+package mckio
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PromptGateDialog(t *testing.T) {
+	assrt := assert.New(t)
+	dc := NewDuplexConsole([]string{"alice", "42"})
+	pg := NewPromptGate(ExpectRegexp(`\? $`), dc.Reader(), dc.Writer(), time.Second)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		in := bufio.NewReader(pg.Reader())
+		out := pg.Writer()
+		// prompts are written after a delay the input mustn't race
+		time.Sleep(10 * time.Millisecond)
+		fmt.Fprint(out, "name? ")
+		name, _ := in.ReadString('\n')
+		fmt.Fprint(out, "age? ")
+		age, _ := in.ReadString('\n')
+		fmt.Fprintf(out, "%s is %s", name[:len(name)-1], age)
+	}()
+	<-done
+	assrt.Nil(pg.Err())
+	assrt.Equal("name? alice\nage? 42\nalice is 42\n", dc.Transcript())
+}
+func Test_PromptGateTimeout(t *testing.T) {
+	assrt := assert.New(t)
+	dc := NewDuplexConsole([]string{"alice"})
+	pg := NewPromptGate(ExpectContains("name?"), dc.Reader(), nil, 10*time.Millisecond)
+	fmt.Fprint(pg.Writer(), "welcome\n")
+	_, err := pg.Reader().Read(make([]byte, 8))
+	assrt.True(errors.Is(err, os.ErrDeadlineExceeded))
+	assrt.Contains(err.Error(), "welcome")
+	assrt.Equal(err, pg.Err())
+	_, err = io.ReadAll(pg.Reader())
+	assrt.Equal(pg.Err(), err)
+}
+func Test_PromptGateLinesPerRead(t *testing.T) {
+	assrt := assert.New(t)
+	var out strings.Builder
+	pg := NewPromptGate(ExpectContains("? "), strings.NewReader("alice\n42\n"), &out, 20*time.Millisecond)
+	in := bufio.NewReader(pg.Reader())
+	fmt.Fprint(pg.Writer(), "name? ")
+	name, err := in.ReadString('\n')
+	assrt.Nil(err)
+	assrt.Equal("alice\n", name)
+	// the second line is withheld until its prompt is written.
+	_, err = in.ReadString('\n')
+	assrt.True(errors.Is(err, os.ErrDeadlineExceeded))
+	assrt.Equal(err, pg.Err())
+}
+func Test_PromptGateLinesPerReadPrompted(t *testing.T) {
+	assrt := assert.New(t)
+	pg := NewPromptGate(ExpectContains("? "), strings.NewReader("alice\n42\n"), nil, time.Second)
+	in := bufio.NewReader(pg.Reader())
+	var lines []string
+	for _, prompt := range []string{"name? ", "age? "} {
+		fmt.Fprint(pg.Writer(), prompt)
+		line, err := in.ReadString('\n')
+		assrt.Nil(err)
+		lines = append(lines, line)
+	}
+	assrt.Equal([]string{"alice\n", "42\n"}, lines)
+	fmt.Fprint(pg.Writer(), "more? ")
+	_, err := in.ReadString('\n')
+	assrt.Equal(io.EOF, err)
+	assrt.Nil(pg.Err())
+}
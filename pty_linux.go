@@ -0,0 +1,63 @@
+package mckio
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// opens a pseudo-terminal returning its master and its terminal (slave).
+func openPty() (master *os.File, tty *os.File, err error) {
+	master, err = os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	var unlock int32
+	if err := ioctl(master.Fd(), syscall.TIOCSPTLCK, uintptr(unsafe.Pointer(&unlock))); err != nil {
+		master.Close()
+		return nil, nil, os.NewSyscallError("ioctl TIOCSPTLCK", err)
+	}
+	var num uint32
+	if err := ioctl(master.Fd(), syscall.TIOCGPTN, uintptr(unsafe.Pointer(&num))); err != nil {
+		master.Close()
+		return nil, nil, os.NewSyscallError("ioctl TIOCGPTN", err)
+	}
+	tty, err = os.OpenFile("/dev/pts/"+strconv.Itoa(int(num)), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+	return master, tty, nil
+}
+func isTerminal(fd uintptr) bool {
+	var termios syscall.Termios
+	return ioctl(fd, syscall.TCGETS, uintptr(unsafe.Pointer(&termios))) == nil
+}
+func ttyEchoing(fd uintptr) (bool, error) {
+	var termios syscall.Termios
+	if err := ioctl(fd, syscall.TCGETS, uintptr(unsafe.Pointer(&termios))); err != nil {
+		return false, os.NewSyscallError("ioctl TCGETS", err)
+	}
+	return termios.Lflag&syscall.ECHO != 0, nil
+}
+func ttyResize(fd uintptr, cols, rows int) error {
+	ws := struct{ row, col, xpixel, ypixel uint16 }{row: uint16(rows), col: uint16(cols)}
+	if err := ioctl(fd, syscall.TIOCSWINSZ, uintptr(unsafe.Pointer(&ws))); err != nil {
+		return os.NewSyscallError("ioctl TIOCSWINSZ", err)
+	}
+	return nil
+}
+func ttySize(fd uintptr) (cols, rows int, err error) {
+	var ws struct{ row, col, xpixel, ypixel uint16 }
+	if err := ioctl(fd, syscall.TIOCGWINSZ, uintptr(unsafe.Pointer(&ws))); err != nil {
+		return 0, 0, os.NewSyscallError("ioctl TIOCGWINSZ", err)
+	}
+	return int(ws.col), int(ws.row), nil
+}
+func ioctl(fd uintptr, req uintptr, arg uintptr) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, req, arg); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+//go:build !linux
+
+package mckio
+
+import (
+	"errors"
+	"os"
+)
+
+func openPty() (master *os.File, tty *os.File, err error) {
+	return nil, nil, errors.New("mckio: pseudo-terminals unsupported on this platform")
+}
+func isTerminal(fd uintptr) bool {
+	return false
+}
+func ttyEchoing(fd uintptr) (bool, error) {
+	return false, errors.New("mckio: pseudo-terminals unsupported on this platform")
+}
+func ttyResize(fd uintptr, cols, rows int) error {
+	return errors.New("mckio: pseudo-terminals unsupported on this platform")
+}
+func ttySize(fd uintptr) (cols, rows int, err error) {
+	return 0, 0, errors.New("mckio: pseudo-terminals unsupported on this platform")
+}
@@ -0,0 +1,164 @@
+package mckio
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+/*
+PtyConsole simulates a console using a pseudo-terminal, so a program
+branching on whether its standard streams are terminals, to emit colors,
+prompts, or progress bars, exercises its interactive behavior.  Assign Tty
+to os.Stdin, os.Stdout, and/or os.Stderr, or supply it to a child process.
+
+- The scripted input is copied to the terminal as though typed.  Layer the
+behaviors of mckio's readers, like Rstrings with a delimiter or
+NewKeystrokes, to script it.
+
+- Everything written to the terminal is recorded in Output.  The terminal
+begins in cooked mode, so it echoes the typed input and translates "\n"
+written to it into "\r\n".
+
+- TypeHidden scripts the response to a password prompt, typing it once the
+program disables echo, like golang.org/x/term.ReadPassword does.
+
+- Available on Linux.  Elsewhere NewPtyConsole returns an error.
+
+PtyConsole is concurrency safe.
+*/
+type PtyConsole struct {
+	master    *os.File
+	tty       *os.File
+	output    *Wrecorder
+	drained   chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+/*
+NewPtyConsole opens a pseudo-terminal feeding it the content of 'input'.  A
+nil 'input' types nothing.  A reader that blocks, instead of returning
+io.EOF, continues typing until Close.
+*/
+func NewPtyConsole(input io.Reader) (*PtyConsole, error) {
+	master, tty, err := openPty()
+	if err != nil {
+		return nil, err
+	}
+	pc := &PtyConsole{
+		master:  master,
+		tty:     tty,
+		output:  NewWrecorder(),
+		drained: make(chan struct{}),
+	}
+	if input != nil {
+		go io.Copy(master, input)
+	}
+	go func() {
+		defer close(pc.drained)
+		// reading fails, instead of reporting end of file, once the
+		// terminal is closed.
+		io.Copy(pc.output, master)
+	}()
+	return pc, nil
+}
+
+/*
+Tty returns the terminal end of the pseudo-terminal.
+*/
+func (pc *PtyConsole) Tty() *os.File {
+	return pc.tty
+}
+
+/*
+Output returns the recording of everything written to the terminal,
+including the echo of typed input.  Use its WaitFor method to synchronize
+with a prompt.
+*/
+func (pc *PtyConsole) Output() *Wrecorder {
+	return pc.output
+}
+
+/*
+Echoing reports whether the terminal currently echoes typed input.
+Programs reading a password, like golang.org/x/term.ReadPassword, disable
+echo while reading it.
+*/
+func (pc *PtyConsole) Echoing() (bool, error) {
+	return ttyEchoing(pc.tty.Fd())
+}
+
+/*
+TypeHidden waits until the program disables the terminal's echo to read a
+password, then types 'secret' followed by a newline, so the secret doesn't
+appear in Output.  Typing it earlier would echo it, as a terminal echoes
+input when it's typed, not when it's read.  The error returned when echo
+isn't disabled within 'timeout' wraps os.ErrDeadlineExceeded.
+*/
+func (pc *PtyConsole) TypeHidden(secret string, timeout time.Duration) error {
+	expire := time.NewTimer(timeout)
+	defer expire.Stop()
+	poll := time.NewTicker(time.Millisecond)
+	defer poll.Stop()
+	for {
+		echoing, err := pc.Echoing()
+		if err != nil {
+			return err
+		}
+		if !echoing {
+			_, err := io.WriteString(pc.master, secret+"\n")
+			return err
+		}
+		select {
+		case <-expire.C:
+			return fmt.Errorf("mckio: terminal echo not disabled within %v: %w", timeout, os.ErrDeadlineExceeded)
+		case <-poll.C:
+		}
+	}
+}
+
+/*
+Resize changes the dimensions of the terminal, which the code under test
+observes by querying its size, like golang.org/x/term.GetSize.  The kernel
+sends SIGWINCH only to a process for which the terminal is the controlling
+terminal, like a child process started in a new session.  Use TermSize to
+simulate the signal for the test's own process.
+*/
+func (pc *PtyConsole) Resize(cols, rows int) error {
+	return ttyResize(pc.tty.Fd(), cols, rows)
+}
+
+/*
+Size returns the current width and height of the terminal.
+*/
+func (pc *PtyConsole) Size() (width, height int, err error) {
+	return ttySize(pc.tty.Fd())
+}
+
+/*
+Close closes the terminal, waits until the output written to it has been
+recorded, then closes the pseudo-terminal.  Calling it more than once
+returns the same result.
+*/
+func (pc *PtyConsole) Close() error {
+	pc.closeOnce.Do(func() {
+		pc.closeErr = pc.tty.Close()
+		<-pc.drained
+		if err := pc.master.Close(); err != nil && pc.closeErr == nil {
+			pc.closeErr = err
+		}
+	})
+	return pc.closeErr
+}
+
+/*
+IsTerminal reports whether 'f' is a terminal, like the terminal returned by
+PtyConsole.Tty, which is how programs typically decide whether they're
+interactive.
+*/
+func IsTerminal(f *os.File) bool {
+	return isTerminal(f.Fd())
+}
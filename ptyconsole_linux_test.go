@@ -0,0 +1,94 @@
+package mckio
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PtyConsole(t *testing.T) {
+	assrt := assert.New(t)
+	in := NewRstrings([]string{"alice"}, delimAdd{})
+	pc, err := NewPtyConsole(&in)
+	if err != nil {
+		t.Skipf("pseudo-terminal unavailable: %v", err)
+	}
+	defer pc.Close()
+	assrt.True(IsTerminal(pc.Tty()))
+	fmt.Fprint(pc.Tty(), "name? ")
+	name, err := bufio.NewReader(pc.Tty()).ReadString('\n')
+	assrt.Nil(err)
+	assrt.Equal("alice\n", name)
+	fmt.Fprintf(pc.Tty(), "hello %s", name)
+	assrt.Nil(pc.Output().WaitFor("hello alice\r\n", time.Second))
+	assrt.Nil(pc.Close())
+	// typed input is echoed
+	assrt.Contains(pc.Output().String(), "alice\r\n")
+}
+func Test_IsTerminalPipe(t *testing.T) {
+	assrt := assert.New(t)
+	rdr, wrt, err := os.Pipe()
+	assrt.Nil(err)
+	defer rdr.Close()
+	defer wrt.Close()
+	assrt.False(IsTerminal(wrt))
+}
+func Test_PtyConsoleTypeHidden(t *testing.T) {
+	assrt := assert.New(t)
+	pc, err := NewPtyConsole(nil)
+	if err != nil {
+		t.Skipf("pseudo-terminal unavailable: %v", err)
+	}
+	defer pc.Close()
+	echoing, err := pc.Echoing()
+	assrt.Nil(err)
+	assrt.True(echoing)
+	typed := make(chan error, 1)
+	go func() { typed <- pc.TypeHidden("s3cret", time.Second) }()
+	// like golang.org/x/term.ReadPassword
+	fmt.Fprint(pc.Tty(), "password: ")
+	fd := pc.Tty().Fd()
+	var termios syscall.Termios
+	assrt.Nil(ioctl(fd, syscall.TCGETS, uintptr(unsafe.Pointer(&termios))))
+	restore := termios
+	termios.Lflag &^= syscall.ECHO
+	assrt.Nil(ioctl(fd, syscall.TCSETS, uintptr(unsafe.Pointer(&termios))))
+	secret, err := bufio.NewReader(pc.Tty()).ReadString('\n')
+	assrt.Nil(ioctl(fd, syscall.TCSETS, uintptr(unsafe.Pointer(&restore))))
+	assrt.Nil(err)
+	assrt.Nil(<-typed)
+	assrt.Equal("s3cret\n", secret)
+	fmt.Fprint(pc.Tty(), "ok\n")
+	assrt.Nil(pc.Output().WaitFor("ok", time.Second))
+	assrt.NotContains(pc.Output().String(), "s3cret")
+}
+func Test_PtyConsoleTypeHiddenTimeout(t *testing.T) {
+	assrt := assert.New(t)
+	pc, err := NewPtyConsole(nil)
+	if err != nil {
+		t.Skipf("pseudo-terminal unavailable: %v", err)
+	}
+	defer pc.Close()
+	err = pc.TypeHidden("s3cret", 10*time.Millisecond)
+	assrt.True(errors.Is(err, os.ErrDeadlineExceeded))
+}
+func Test_PtyConsoleResize(t *testing.T) {
+	assrt := assert.New(t)
+	pc, err := NewPtyConsole(nil)
+	if err != nil {
+		t.Skipf("pseudo-terminal unavailable: %v", err)
+	}
+	defer pc.Close()
+	assrt.Nil(pc.Resize(132, 43))
+	cols, rows, err := pc.Size()
+	assrt.Nil(err)
+	assrt.Equal(132, cols)
+	assrt.Equal(43, rows)
+}
@@ -0,0 +1,33 @@
+package mckio
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+/*
+NewRbase64 creates an io.Reader delivering the bytes encoded by the base64
+strings 'fixtures', so small binary fixtures can be embedded in test source
+without external files.
+
+- Each fixture is decoded using the standard base64 alphabet.  Whitespace,
+like the line breaks of wrapped output, is ignored, as is missing padding.
+
+- The reader is an Rchan delivering each decoded fixture as a message,
+therefore, its behavior can be configured using the behaviors accepted by
+NewChanBehavior, like BehaviorChunkSizer to produce short reads, or
+BehaviorBlockBeforeEachReader to delay them.
+
+It returns an error, identifying the fixture, when one can't be decoded.
+*/
+func NewRbase64(fixtures []string, behavior interface{}) (rdr Rchan, err error) {
+	data := make([][]byte, len(fixtures))
+	for i, f := range fixtures {
+		enc := strings.TrimRight(strings.Join(strings.Fields(f), ""), "=")
+		if data[i], err = base64.RawStdEncoding.DecodeString(enc); err != nil {
+			return rdr, fmt.Errorf("mckio: base64 fixture %d: %w", i+1, err)
+		}
+	}
+	return fixtureReader(behavior, data...), nil
+}
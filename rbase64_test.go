@@ -0,0 +1,39 @@
+package mckio
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Rbase64(t *testing.T) {
+	assrt := assert.New(t)
+	rdr, err := NewRbase64([]string{"SGVsbG8=", "LCB3b3Js\nZA", "AAH/"}, nil)
+	assrt.Nil(err)
+	out, err := io.ReadAll(&rdr)
+	assrt.Nil(err)
+	assrt.Equal("Hello, world\x00\x01\xff", string(out))
+}
+func Test_Rbase64ShortReads(t *testing.T) {
+	assrt := assert.New(t)
+	rdr, err := NewRbase64([]string{"AAECAwQ="}, chunkSize(2))
+	assrt.Nil(err)
+	p := make([]byte, 10)
+	var reads [][]byte
+	for {
+		n, err := rdr.Read(p)
+		if err != nil {
+			break
+		}
+		reads = append(reads, append([]byte(nil), p[:n]...))
+	}
+	assrt.Equal([][]byte{{0, 1}, {2, 3}, {4}}, reads)
+}
+func Test_Rbase64Error(t *testing.T) {
+	assrt := assert.New(t)
+	_, err := NewRbase64([]string{"AAEC", "not base64!"}, nil)
+	if assrt.NotNil(err) {
+		assrt.Contains(err.Error(), "base64 fixture 2")
+	}
+}
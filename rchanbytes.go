@@ -0,0 +1,61 @@
+package mckio
+
+import "io"
+
+/*
+RchanBytes converts a channel streaming byte slices into an io.Reader.  It
+offers the same semantics as Rchan without converting binary payloads
+to and from strings.
+
+- This reader can block because the channel can block.
+
+- The reader will return as many residual bytes from the previous
+read before requesting data from the channel.  Therefore, be prepared to
+receive a quantity of bytes less than the length requested by the
+'p []byte' argument.
+
+- Closing the channel returns an io.EOF error.
+
+- The sender must not modify a byte slice after sending it, as the reader
+retains it until its bytes have been consumed.
+
+- RchanBytes is not concurrency safe.
+*/
+type RchanBytes struct {
+	msgs <-chan []byte
+	cur  []byte
+}
+
+/*
+NewChanBytes creates an io.Reader implemented as a receiving channel of
+byte slices.
+*/
+func NewChanBytes(msgs <-chan []byte) (rdr RchanBytes) {
+	return RchanBytes{msgs: msgs}
+}
+
+/*
+Read implements an io.Reader based on a channel conforming to
+io.Reader semantics (https://golang.org/pkg/io/#Reader).
+*/
+func (rb *RchanBytes) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		// because channel can block - return do nothing request instead
+		// of blocking and then returning nothing.
+		return 0, nil
+	}
+	for {
+		if len(rb.cur) > 0 {
+			// have something to return.  do so before
+			// possibly blocking on channel.
+			n := copy(p, rb.cur)
+			rb.cur = rb.cur[n:]
+			return n, nil
+		}
+		var ok bool
+		rb.cur, ok = <-rb.msgs
+		if !ok {
+			return 0, io.EOF
+		}
+	}
+}
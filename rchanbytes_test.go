@@ -0,0 +1,46 @@
+package mckio
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RchanBytesBinarySegmentAcrossCalls(t *testing.T) {
+	assrt := assert.New(t)
+	msgs := make(chan []byte)
+	rdr := NewChanBytes(msgs)
+	msg := []byte{0x00, 0xff, 0xfe, 0x80, 0x0a}
+	go func() {
+		defer close(msgs)
+		msgs <- msg
+		// empty message shouldn't generate a zero length read
+		msgs <- []byte{}
+		msgs <- msg
+	}()
+	buf := make([]byte, 3)
+	sz, err := rdr.Read(buf)
+	assrt.Equal(3, sz)
+	assrt.Nil(err)
+	assrt.Equal(msg[:3], buf)
+	sz, err = rdr.Read(buf)
+	assrt.Equal(2, sz)
+	assrt.Nil(err)
+	assrt.Equal(msg[3:], buf[:sz])
+	all := make([]byte, 16)
+	sz, err = rdr.Read(all)
+	assrt.Equal(len(msg), sz)
+	assrt.Equal(msg, all[:sz])
+	sz, err = rdr.Read(all)
+	assrt.Zero(sz)
+	assrt.IsType(io.EOF, err)
+}
+func Test_RchanBytesReadDoNothing(t *testing.T) {
+	assrt := assert.New(t)
+	var msgs chan []byte
+	rdr := NewChanBytes(msgs)
+	sz, err := rdr.Read(nil)
+	assrt.Zero(sz)
+	assrt.Nil(err)
+}
@@ -0,0 +1,39 @@
+package mckio
+
+import "sync"
+
+/*
+NewChanFanIn creates an Rchan that merges messages from several producer
+channels into a single io.Reader, simulating merged log or console feeds.
+Messages are delivered in the order they arrive.  The reader returns
+io.EOF only after every channel has been closed.
+
+- Messages from a single channel retain their relative order.
+
+- A forwarding goroutine is started for each channel.  It terminates once
+its channel is closed.
+*/
+func NewChanFanIn(chs ...<-chan string) (rdr Rchan) {
+	merged := make(chan string)
+	var wg sync.WaitGroup
+	wg.Add(len(chs))
+	for _, ch := range chs {
+		go fanIn(ch, merged, &wg)
+	}
+	go func() {
+		wg.Wait()
+		close(merged)
+	}()
+	return NewChan(merged)
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+func fanIn(ch <-chan string, merged chan<- string, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for msg := range ch {
+		merged <- msg
+	}
+}
@@ -0,0 +1,57 @@
+package mckio
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RchanFanInArrivalOrder(t *testing.T) {
+	assrt := assert.New(t)
+	logs := make(chan string)
+	console := make(chan string)
+	rdr := NewChanFanIn(logs, console)
+	p := make([]byte, 16)
+	read := func() string {
+		sz, err := rdr.Read(p)
+		assrt.Nil(err)
+		return string(p[:sz])
+	}
+	go func() { logs <- "log 1" }()
+	assrt.Equal("log 1", read())
+	go func() { console <- "cmd 1" }()
+	assrt.Equal("cmd 1", read())
+	go func() { logs <- "log 2" }()
+	assrt.Equal("log 2", read())
+	close(logs)
+	go func() { console <- "cmd 2" }()
+	// remains open until all channels close
+	assrt.Equal("cmd 2", read())
+	close(console)
+	sz, err := rdr.Read(p)
+	assrt.Zero(sz)
+	assrt.IsType(io.EOF, err)
+}
+func Test_RchanFanInAllMessages(t *testing.T) {
+	assrt := assert.New(t)
+	var chs []<-chan string
+	for _, src := range []string{"a", "b", "c"} {
+		ch := make(chan string)
+		go func(src string) {
+			defer close(ch)
+			for i := 0; i < 3; i++ {
+				ch <- src + "\n"
+			}
+		}(src)
+		chs = append(chs, ch)
+	}
+	rdr := NewChanFanIn(chs...)
+	all, err := io.ReadAll(&rdr)
+	assrt.Nil(err)
+	lines := strings.Fields(string(all))
+	sort.Strings(lines)
+	assrt.Equal([]string{"a", "a", "a", "b", "b", "b", "c", "c", "c"}, lines)
+}
@@ -0,0 +1,278 @@
+package mckio
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+/*
+NewChanPushDelay creates an Rchan that owns its channel, like NewChanPush,
+except each pushed message is scheduled to arrive after its own delay.
+Delays accumulate: a message arrives 'delay' after the preceding message's
+scheduled arrival or, when the preceding message already arrived, 'delay'
+after being pushed.  Arrival times are therefore independent of when the
+consumer calls Read, so bursty-then-quiet patterns can be expressed
+precisely.  For example, pushing three messages with delays of 0, 0, and
+1 second delivers a burst of two followed by one a second later.
+
+- push never blocks, as scheduled messages are queued internally.
+
+- depth - specifies the channel's buffer size.  Messages that have
+arrived but can't be buffered wait until the reader receives them, without
+shifting the arrival of subsequent messages.
+
+- end closes the channel once the last pushed message has arrived and been
+sent on the channel, which, when the channel is buffered, may precede the
+reader receiving it.  The reader receives the buffered messages before
+io.EOF.  Calling 'end' more than once is harmless, however,
+messages pushed after 'end' are ignored.
+
+- A goroutine delivers the messages until 'end' is called and the last
+message is sent, or until the reader is closed.  Therefore, call 'end'
+and, when the consumer may stop reading before receiving every message,
+Close the reader, for example, using defer, to avoid leaking it.
+
+The following behavior of NewChanPushDelay can be configured:
+
+- BehaviorClocker (optional) - supplies the Clock measuring the delays.
+When undefined - uses the system clock.
+*/
+func NewChanPushDelay(depth int, behavior interface{}) (rdr Rchan, push func(msg string, delay time.Duration), end func()) {
+	cmdLn := make(chan string, depth)
+	rdr = NewChan(cmdLn)
+	sched := &arrivalSchedule{
+		out:   cmdLn,
+		clock: clockOf(behavior),
+		wake:  make(chan struct{}, 1),
+		done:  rdr.abort.done,
+	}
+	go sched.deliver()
+	return rdr, sched.push, sched.end
+}
+
+/*
+PushQueue is a bounded queue of messages feeding an Rchan, mirroring the
+limited buffers of real devices, so producer-side back-pressure handling
+in test harness code can be exercised.  Once the queue holds its maximum
+number of pending messages, Push either blocks until the reader receives
+a message or returns ErrQueueFull.
+
+- Stats reports the queue's activity.
+
+- PushQueue is concurrency safe.
+*/
+type PushQueue struct {
+	mu      sync.Mutex
+	endMu   sync.RWMutex
+	msgs    chan string
+	block   bool
+	ended   chan struct{}
+	endOnce sync.Once
+	stats   QueueStats
+}
+
+/*
+QueueStats reports the activity of a PushQueue.
+
+- Pushed - number of messages accepted by the queue.
+
+- Rejected - number of messages refused because the queue was full.
+
+- Blocked - number of pushes that waited for room in the queue.
+
+- HighWater - maximum number of pending messages observed.
+
+- Pending - number of messages waiting to be received by the reader.
+*/
+type QueueStats struct {
+	Pushed    int
+	Rejected  int
+	Blocked   int
+	HighWater int
+	Pending   int
+}
+
+/*
+ErrQueueFull is returned by a PushQueue configured to refuse, instead of
+block, a message that exceeds its capacity.
+*/
+var ErrQueueFull = errors.New("mckio: push queue full")
+
+/*
+NewChanQueue creates an Rchan fed by a PushQueue holding at most 'max'
+pending messages.  When 'blockWhenFull' is true, Push blocks while the queue
+is full, otherwise it returns ErrQueueFull.
+*/
+func NewChanQueue(max int, blockWhenFull bool) (rdr Rchan, queue *PushQueue) {
+	queue = &PushQueue{
+		msgs:  make(chan string, max),
+		block: blockWhenFull,
+		ended: make(chan struct{}),
+	}
+	return NewChan(queue.msgs), queue
+}
+
+/*
+Push adds a message to the queue.  Pushing after End returns
+io.ErrClosedPipe, as does a Push blocked when End is called.
+*/
+func (pq *PushQueue) Push(msg string) error {
+	pq.endMu.RLock()
+	defer pq.endMu.RUnlock()
+	select {
+	case <-pq.ended:
+		// channel may be closed - avoid sending on it.
+		return io.ErrClosedPipe
+	default:
+	}
+	select {
+	case pq.msgs <- msg:
+		pq.accepted()
+		return nil
+	default:
+	}
+	pq.mu.Lock()
+	if !pq.block {
+		pq.stats.Rejected++
+		pq.mu.Unlock()
+		return ErrQueueFull
+	}
+	pq.stats.Blocked++
+	pq.mu.Unlock()
+	select {
+	case <-pq.ended:
+		return io.ErrClosedPipe
+	case pq.msgs <- msg:
+		pq.accepted()
+		return nil
+	}
+}
+
+/*
+End closes the queue, so the reader returns io.EOF after receiving the
+pending messages.  Calling it more than once is harmless.
+*/
+func (pq *PushQueue) End() {
+	pq.endOnce.Do(func() {
+		close(pq.ended)
+		// wait for pushes in progress to complete before closing channel.
+		pq.endMu.Lock()
+		defer pq.endMu.Unlock()
+		close(pq.msgs)
+	})
+}
+
+/*
+Stats reports the queue's activity.
+*/
+func (pq *PushQueue) Stats() QueueStats {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	stats := pq.stats
+	stats.Pending = len(pq.msgs)
+	return stats
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+type arrival struct {
+	msg string
+	at  time.Time
+}
+type arrivalSchedule struct {
+	mu    sync.Mutex
+	queue []arrival
+	last  time.Time
+	ended bool
+	out   chan<- string
+	clock Clock
+	// signals deliver that a message was pushed or 'end' called.
+	wake chan struct{}
+	// closed once the reader is closed.
+	done <-chan struct{}
+}
+
+func (pq *PushQueue) accepted() {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	pq.stats.Pushed++
+	if pending := len(pq.msgs); pending > pq.stats.HighWater {
+		pq.stats.HighWater = pending
+	}
+}
+func (as *arrivalSchedule) push(msg string, delay time.Duration) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	if as.ended {
+		return
+	}
+	at := as.clock.Now()
+	if as.last.After(at) {
+		at = as.last
+	}
+	as.last = at.Add(delay)
+	as.queue = append(as.queue, arrival{msg: msg, at: as.last})
+	as.signal()
+}
+func (as *arrivalSchedule) end() {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	as.ended = true
+	as.signal()
+}
+func (as *arrivalSchedule) signal() {
+	select {
+	case as.wake <- struct{}{}:
+	default:
+	}
+}
+
+// sends each message on arrival until 'end' is called and the queue is
+// empty, or the reader is closed.
+func (as *arrivalSchedule) deliver() {
+	defer close(as.out)
+	for {
+		as.mu.Lock()
+		if len(as.queue) < 1 {
+			ended := as.ended
+			as.mu.Unlock()
+			if ended {
+				return
+			}
+			select {
+			case <-as.wake:
+			case <-as.done:
+				return
+			}
+			continue
+		}
+		next := as.queue[0]
+		as.queue = as.queue[1:]
+		as.mu.Unlock()
+		if !as.arrive(next) {
+			return
+		}
+	}
+}
+
+// waits for the message's arrival then sends it, reporting whether it was
+// sent before the reader was closed.
+func (as *arrivalSchedule) arrive(next arrival) bool {
+	tmr := as.clock.NewTimer(next.at.Sub(as.clock.Now()))
+	defer tmr.Stop()
+	select {
+	case <-tmr.C():
+	case <-as.done:
+		return false
+	}
+	select {
+	case as.out <- next.msg:
+		return true
+	case <-as.done:
+		return false
+	}
+}
@@ -0,0 +1,153 @@
+package mckio
+
+import (
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RchanPushDelayBurstThenQuiet(t *testing.T) {
+	assrt := assert.New(t)
+	rdr, push, end := NewChanPushDelay(0, nil)
+	start := time.Now()
+	push("burst 1", 0)
+	push("burst 2", 0)
+	push("quiet", 50*time.Millisecond)
+	end()
+	p := make([]byte, 16)
+	var arrived []time.Duration
+	for {
+		sz, err := rdr.Read(p)
+		if err != nil {
+			assrt.IsType(io.EOF, err)
+			break
+		}
+		assrt.NotZero(sz)
+		arrived = append(arrived, time.Since(start))
+	}
+	assrt.Len(arrived, 3)
+	assrt.Less(int64(arrived[1]), int64(50*time.Millisecond))
+	assrt.GreaterOrEqual(int64(arrived[2]), int64(50*time.Millisecond))
+}
+func Test_RchanPushDelayIndependentOfRead(t *testing.T) {
+	assrt := assert.New(t)
+	rdr, push, end := NewChanPushDelay(2, nil)
+	start := time.Now()
+	push("first", 20*time.Millisecond)
+	push("second", 20*time.Millisecond)
+	end()
+	// consumer arrives late: both messages should be available
+	// at once as their arrival times have passed.
+	time.Sleep(60 * time.Millisecond)
+	p := make([]byte, 16)
+	rdr.Read(p)
+	rdr.Read(p)
+	assrt.Less(int64(time.Since(start)), int64(80*time.Millisecond))
+}
+func Test_RchanPushDelayClock(t *testing.T) {
+	assrt := assert.New(t)
+	fc := NewFakeClock(time.Time{})
+	rdr, push, end := NewChanPushDelay(0, fc)
+	push("first", time.Second)
+	push("second", time.Second)
+	end()
+	fakeClockWait(fc, 1)
+	assrt.Equal(time.Second, fc.Waits()[0].Duration)
+	fc.Advance(time.Second)
+	p := make([]byte, 16)
+	sz, _ := rdr.Read(p)
+	assrt.Equal("first", string(p[:sz]))
+	fakeClockWait(fc, 1)
+	fc.Advance(time.Second)
+	sz, _ = rdr.Read(p)
+	assrt.Equal("second", string(p[:sz]))
+	_, err := rdr.Read(p)
+	assrt.Equal(io.EOF, err)
+}
+func Test_RchanPushDelayEndUnread(t *testing.T) {
+	assrt := assert.New(t)
+	before := delivering()
+	rdr, push, end := NewChanPushDelay(0, nil)
+	push("unread", 0)
+	end()
+	assrt.True(awaitDelivering(before + 1))
+	// the consumer stops reading with the message pending.
+	rdr.Close()
+	assrt.True(awaitDelivering(before))
+}
+
+// waits up to a second for 'n' goroutines to deliver messages.
+func awaitDelivering(n int) bool {
+	return awaitGoroutines("(*arrivalSchedule).deliver(", n)
+}
+
+// counts the goroutines delivering the messages of NewChanPushDelay.
+func delivering() int {
+	return goroutines("(*arrivalSchedule).deliver(")
+}
+
+// waits up to a second for 'n' goroutines to be executing 'frame'.
+func awaitGoroutines(frame string, n int) bool {
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); {
+		if goroutines(frame) == n {
+			return true
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return false
+}
+
+// counts the goroutines whose stack includes 'frame'.
+func goroutines(frame string) int {
+	buf := make([]byte, 1<<20)
+	return strings.Count(string(buf[:runtime.Stack(buf, true)]), frame)
+}
+func Test_PushQueueReject(t *testing.T) {
+	assrt := assert.New(t)
+	rdr, queue := NewChanQueue(2, false)
+	assrt.Nil(queue.Push("cmmd 1"))
+	assrt.Nil(queue.Push("cmmd 2"))
+	assrt.Equal(ErrQueueFull, queue.Push("cmmd 3"))
+	assrt.Equal(QueueStats{Pushed: 2, Rejected: 1, HighWater: 2, Pending: 2}, queue.Stats())
+	p := make([]byte, 16)
+	sz, _ := rdr.Read(p)
+	assrt.Equal("cmmd 1", string(p[:sz]))
+	assrt.Nil(queue.Push("cmmd 3"))
+	queue.End()
+	queue.End()
+	assrt.Equal(io.ErrClosedPipe, queue.Push("cmmd 4"))
+	var rslt string
+	for {
+		sz, err := rdr.Read(p)
+		rslt += string(p[:sz])
+		if err != nil {
+			break
+		}
+	}
+	assrt.Equal("cmmd 2cmmd 3", rslt)
+}
+func Test_PushQueueBlock(t *testing.T) {
+	assrt := assert.New(t)
+	rdr, queue := NewChanQueue(1, true)
+	assrt.Nil(queue.Push("cmmd 1"))
+	pushed := make(chan error)
+	go func() { pushed <- queue.Push("cmmd 2") }()
+	select {
+	case <-pushed:
+		assrt.Fail("push should block while queue is full")
+	case <-time.After(10 * time.Millisecond):
+	}
+	p := make([]byte, 16)
+	rdr.Read(p)
+	assrt.Nil(<-pushed)
+	assrt.Equal(1, queue.Stats().Blocked)
+	// end releases a blocked push
+	go func() { pushed <- queue.Push("cmmd 3") }()
+	time.Sleep(10 * time.Millisecond)
+	queue.End()
+	assrt.Equal(io.ErrClosedPipe, <-pushed)
+}
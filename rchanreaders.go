@@ -0,0 +1,70 @@
+package mckio
+
+import "io"
+
+/*
+RchanReaders converts a channel streaming io.Readers into a single
+io.Reader that concatenates them.  Each received reader is drained until
+it returns io.EOF before the next one is received, allowing producers to
+stream large fixtures, like files or bytes.Readers, without converting
+them into strings.
+
+- This reader can block because the channel can block.
+
+- A read returns bytes from only one of the received readers.
+
+- Errors, other than io.EOF, produced by a received reader are returned
+unchanged.  The failing reader remains current, so subsequent reads
+consult it again.
+
+- Closing the channel returns an io.EOF error.
+
+- Received readers are not closed.  The producer remains responsible
+for closing them.
+
+- RchanReaders is not concurrency safe.
+*/
+type RchanReaders struct {
+	rdrs <-chan io.Reader
+	cur  io.Reader
+}
+
+/*
+NewChanReaders creates an io.Reader implemented as a receiving channel of
+io.Readers.
+*/
+func NewChanReaders(rdrs <-chan io.Reader) (rdr RchanReaders) {
+	return RchanReaders{rdrs: rdrs}
+}
+
+/*
+Read implements an io.Reader based on a channel conforming to
+io.Reader semantics (https://golang.org/pkg/io/#Reader).
+*/
+func (rr *RchanReaders) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		// because channel can block - return do nothing request instead
+		// of blocking and then returning nothing.
+		return 0, nil
+	}
+	for {
+		if rr.cur != nil {
+			n, err := rr.cur.Read(p)
+			if err == io.EOF {
+				// current reader exhausted.  return what it
+				// provided before receiving the next one.
+				rr.cur = nil
+				err = nil
+			}
+			if n > 0 || err != nil {
+				return n, err
+			}
+			continue
+		}
+		var ok bool
+		rr.cur, ok = <-rr.rdrs
+		if !ok {
+			return 0, io.EOF
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package mckio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RchanReadersConcatenate(t *testing.T) {
+	assrt := assert.New(t)
+	rdrs := make(chan io.Reader)
+	rdr := NewChanReaders(rdrs)
+	go func() {
+		defer close(rdrs)
+		rdrs <- strings.NewReader("first ")
+		rdrs <- bytes.NewReader(nil)
+		rdrs <- bytes.NewReader([]byte("second "))
+		s := NewRstrings([]string{"third"}, nil)
+		rdrs <- &s
+	}()
+	all, err := io.ReadAll(&rdr)
+	assrt.Nil(err)
+	assrt.Equal("first second third", string(all))
+	sz, err := rdr.Read(make([]byte, 1))
+	assrt.Zero(sz)
+	assrt.IsType(io.EOF, err)
+}
+func Test_RchanReadersError(t *testing.T) {
+	assrt := assert.New(t)
+	rdrs := make(chan io.Reader, 1)
+	rdr := NewChanReaders(rdrs)
+	errRd := errors.New("fixture unavailable")
+	rdrs <- io.MultiReader(strings.NewReader("ok"), errReader{errRd})
+	p := make([]byte, 8)
+	sz, err := rdr.Read(p)
+	assrt.Equal("ok", string(p[:sz]))
+	assrt.Nil(err)
+	sz, err = rdr.Read(p)
+	assrt.Zero(sz)
+	assrt.Equal(errRd, err)
+}
+
+type errReader struct {
+	err error
+}
+
+func (er errReader) Read(p []byte) (int, error) {
+	return 0, er.err
+}
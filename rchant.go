@@ -0,0 +1,61 @@
+package mckio
+
+import "io"
+
+/*
+RchanT converts a channel streaming values of any type into an io.Reader.
+Each received value is serialized by an encoder function, allowing tests to
+stream structured messages, like structs or protos, instead of encoding
+them into strings at every send site.
+
+- This reader can block because the channel can block.
+
+- The reader will return as many residual bytes from the previous
+read before requesting data from the channel.  Therefore, be prepared to
+receive a quantity of bytes less than the length requested by the
+'p []byte' argument.
+
+- Closing the channel returns an io.EOF error.
+
+- RchanT is not concurrency safe.
+*/
+type RchanT[T any] struct {
+	msgs   <-chan T
+	encode func(T) []byte
+	cur    []byte
+}
+
+/*
+NewChanT creates an io.Reader implemented as a receiving channel of values
+serialized by 'encode'.  The encoder is called once per received value, as
+it's received.
+*/
+func NewChanT[T any](ch <-chan T, encode func(T) []byte) (rdr RchanT[T]) {
+	return RchanT[T]{msgs: ch, encode: encode}
+}
+
+/*
+Read implements an io.Reader based on a channel conforming to
+io.Reader semantics (https://golang.org/pkg/io/#Reader).
+*/
+func (rt *RchanT[T]) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		// because channel can block - return do nothing request instead
+		// of blocking and then returning nothing.
+		return 0, nil
+	}
+	for {
+		if len(rt.cur) > 0 {
+			// have something to return.  do so before
+			// possibly blocking on channel.
+			n := copy(p, rt.cur)
+			rt.cur = rt.cur[n:]
+			return n, nil
+		}
+		msg, ok := <-rt.msgs
+		if !ok {
+			return 0, io.EOF
+		}
+		rt.cur = rt.encode(msg)
+	}
+}
@@ -0,0 +1,46 @@
+package mckio
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RchanTEncodeStruct(t *testing.T) {
+	assrt := assert.New(t)
+	type cmd struct {
+		Op  string `json:"op"`
+		Arg int    `json:"arg"`
+	}
+	msgs := make(chan cmd)
+	rdr := NewChanT(msgs, func(c cmd) []byte {
+		enc, _ := json.Marshal(c)
+		return append(enc, '\n')
+	})
+	go func() {
+		defer close(msgs)
+		msgs <- cmd{Op: "add", Arg: 1}
+		msgs <- cmd{Op: "sub", Arg: 2}
+	}()
+	all, err := io.ReadAll(&rdr)
+	assrt.Nil(err)
+	assrt.Equal("{\"op\":\"add\",\"arg\":1}\n{\"op\":\"sub\",\"arg\":2}\n", string(all))
+	sz, err := rdr.Read(make([]byte, 1))
+	assrt.Zero(sz)
+	assrt.IsType(io.EOF, err)
+}
+func Test_RchanTSegmentAcrossCalls(t *testing.T) {
+	assrt := assert.New(t)
+	msgs := make(chan int, 1)
+	rdr := NewChanT(msgs, func(i int) []byte { return []byte{byte(i), byte(i + 1), byte(i + 2)} })
+	msgs <- 7
+	p := make([]byte, 2)
+	sz, err := rdr.Read(p)
+	assrt.Equal([]byte{7, 8}, p[:sz])
+	assrt.Nil(err)
+	sz, err = rdr.Read(p)
+	assrt.Equal([]byte{9}, p[:sz])
+	assrt.Nil(err)
+}
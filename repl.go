@@ -0,0 +1,211 @@
+package mckio
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+/*
+ReplStep is a step of a script driving a REPL-style function run by
+RunRepl: ReplExpect awaits the exact output the function writes next,
+ReplSend types a line of input, and ReplEnd ends the input.
+*/
+type ReplStep struct {
+	step ExpectStep
+}
+
+/*
+ReplExpect awaits 'output' as the next output written to stdout or stderr,
+like a prompt or a command's response.
+*/
+func ReplExpect(output string) ReplStep {
+	return ReplStep{ExpectStep{kind: stepExact, line: output}}
+}
+
+/*
+ReplSend types 'line' followed by a newline.
+*/
+func ReplSend(line string) ReplStep {
+	return ReplStep{Send(line)}
+}
+
+/*
+ReplEnd ends the input, like typing Ctrl-D, so stdin reports io.EOF once
+the input already typed is read.
+*/
+func ReplEnd() ReplStep {
+	return ReplStep{ExpectStep{kind: stepEnd}}
+}
+
+/*
+Within overrides the timeout of a ReplExpect step.
+*/
+func (rs ReplStep) Within(timeout time.Duration) ReplStep {
+	rs.step = rs.step.Within(timeout)
+	return rs
+}
+
+/*
+String describes the step in failure messages.
+*/
+func (rs ReplStep) String() string {
+	return rs.step.String()
+}
+
+/*
+RunRepl runs fn, conducting the dialog described by 'script' with it, then
+fails the test 'tb' when the dialog diverges from the script.
+
+- fn receives the input typed by ReplSend steps through stdin, which
+reports io.EOF after a ReplEnd step or once the script ends.  Output
+written to stdout and stderr is merged, as seen on a terminal.
+
+- Each ReplExpect step fails unless the expected output is written within
+its timeout, which defaults to 'timeout', or when different output is
+written instead.  After the script ends, fn must return within 'timeout'
+without writing further output.
+
+- A failure reports the failed step along with a line diff between the
+transcript the script describes and the transcript produced, which
+interleaves the output written with the input typed.
+
+It returns the transcript produced and whether the dialog matched the
+script.  When fn doesn't return, its goroutine is abandoned.
+*/
+func RunRepl(tb testing.TB, fn func(stdin io.Reader, stdout, stderr io.Writer), timeout time.Duration, script ...ReplStep) (transcript string, ok bool) {
+	tb.Helper()
+	// unbounded buffers, so neither typing nor output waits on the reader.
+	stdin, stdout := NewFilter(nil, nil), NewFilter(nil, nil)
+	es := newExpectSession(struct {
+		io.Reader
+		io.WriteCloser
+	}{stdout, stdin})
+	go es.drain()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer stdout.Close()
+		fn(stdin, stdout, stdout)
+	}()
+	err := replRun(es, timeout, script)
+	stdin.Close()
+	if err == nil {
+		err = replFinish(es, done, timeout)
+	}
+	transcript = es.transcript()
+	if err != nil {
+		var want strings.Builder
+		for _, rs := range script {
+			switch rs.step.kind {
+			case stepExact:
+				want.WriteString(rs.step.line)
+			case stepSend:
+				want.WriteString(rs.step.line + "\n")
+			}
+		}
+		tb.Errorf("mckio: REPL %v\n--- script\n+++ transcript\n%s", err, transcriptDiff(want.String(), transcript))
+		return transcript, false
+	}
+	return transcript, true
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+func replRun(es *expectSession, timeout time.Duration, script []ReplStep) error {
+	for i, rs := range script {
+		step := rs.step
+		if step.timeout == 0 {
+			step.timeout = timeout
+		}
+		if err := es.run(step); err != nil {
+			return fmt.Errorf("step %d %s failed: %w", i+1, step, err)
+		}
+	}
+	return nil
+}
+
+// awaits fn's return, verifying it wrote nothing beyond the script.
+func replFinish(es *expectSession, done <-chan struct{}, timeout time.Duration) error {
+	expire := time.NewTimer(timeout)
+	defer expire.Stop()
+	select {
+	case <-done:
+	case <-expire.C:
+		return fmt.Errorf("function didn't return within %v after the script ended: %w", timeout, os.ErrDeadlineExceeded)
+	}
+	if err := es.run(ExpectEOF().Within(timeout)); err != nil {
+		return err
+	}
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	if len(es.pending) > 0 {
+		return fmt.Errorf("unexpected output \"%s\" after the script ended", excerpt(string(es.pending)))
+	}
+	return nil
+}
+
+// reports whether 'pending' begins with the expected output 'want', failing
+// once 'pending' diverges from it.
+func exactPrefix(pending []byte, want string) (ok bool, err error) {
+	if strings.HasPrefix(string(pending), want) {
+		return true, nil
+	}
+	if !strings.HasPrefix(want, string(pending)) {
+		return false, fmt.Errorf("output \"%s\" differs", excerpt(string(pending)))
+	}
+	return false, nil
+}
+
+// renders a line diff transforming 'want' into 'got', prefixing removed
+// lines with "-", added lines with "+", and common lines with a space.
+func transcriptDiff(want, got string) string {
+	a, b := strings.SplitAfter(want, "\n"), strings.SplitAfter(got, "\n")
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var diff strings.Builder
+	line := func(prefix byte, s string) {
+		if s == "" {
+			return
+		}
+		diff.WriteByte(prefix)
+		diff.WriteString(fmt.Sprintf("%q\n", s))
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			line(' ', a[i])
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			line('-', a[i])
+			i++
+		default:
+			line('+', b[j])
+			j++
+		}
+	}
+	return diff.String()
+}
@@ -0,0 +1,83 @@
+package mckio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func calcRepl(stdin io.Reader, stdout, stderr io.Writer) {
+	scn := bufio.NewScanner(stdin)
+	for fmt.Fprint(stdout, "> "); scn.Scan(); fmt.Fprint(stdout, "> ") {
+		var a, b int
+		if _, err := fmt.Sscanf(scn.Text(), "%d+%d", &a, &b); err != nil {
+			fmt.Fprintln(stderr, "syntax error")
+			continue
+		}
+		fmt.Fprintln(stdout, a+b)
+	}
+	fmt.Fprintln(stdout, "bye")
+}
+func Test_RunRepl(t *testing.T) {
+	assrt := assert.New(t)
+	transcript, ok := RunRepl(t, calcRepl, time.Second,
+		ReplExpect("> "),
+		ReplSend("1+2"),
+		ReplExpect("3\n> "),
+		ReplSend("x"),
+		ReplExpect("syntax error\n"),
+		ReplExpect("> "),
+		ReplEnd(),
+		ReplExpect("bye\n"),
+	)
+	assrt.True(ok)
+	assrt.Equal("> 1+2\n3\n> x\nsyntax error\n> bye\n", transcript)
+}
+func Test_RunReplMismatch(t *testing.T) {
+	assrt := assert.New(t)
+	tr := &tbRecord{TB: t}
+	_, ok := RunRepl(tr, calcRepl, time.Second,
+		ReplExpect("> "),
+		ReplSend("1+2"),
+		ReplExpect("4\n> "),
+		ReplExpect("bye\n"),
+	)
+	assrt.False(ok)
+	if assrt.Len(tr.errs, 1) {
+		assrt.Contains(tr.errs[0], `step 3 expect "4\n> " failed`)
+		assrt.Contains(tr.errs[0], "\n-\"4\\n\"\n")
+		assrt.Contains(tr.errs[0], "\n+\"3\\n\"\n")
+	}
+}
+func Test_RunReplTimeout(t *testing.T) {
+	assrt := assert.New(t)
+	tr := &tbRecord{TB: t}
+	_, ok := RunRepl(tr, calcRepl, 20*time.Millisecond,
+		ReplExpect("> "),
+		ReplExpect("ready"),
+	)
+	assrt.False(ok)
+	if assrt.Len(tr.errs, 1) {
+		assrt.Contains(tr.errs[0], "not satisfied within 20ms")
+	}
+}
+func Test_RunReplUnexpectedOutput(t *testing.T) {
+	assrt := assert.New(t)
+	tr := &tbRecord{TB: t}
+	_, ok := RunRepl(tr, calcRepl, time.Second, ReplExpect("> "))
+	assrt.False(ok)
+	if assrt.Len(tr.errs, 1) {
+		assrt.Contains(tr.errs[0], "unexpected output \"bye\n\"")
+		assrt.True(strings.HasSuffix(tr.errs[0], "\n-\"> \"\n+\"> bye\\n\"\n"))
+	}
+}
+func Test_TranscriptDiff(t *testing.T) {
+	assrt := assert.New(t)
+	assrt.Equal(" \"a\\n\"\n-\"b\\n\"\n+\"x\\n\"\n \"c\"\n", transcriptDiff("a\nb\nc", "a\nx\nc"))
+	assrt.Equal("", transcriptDiff("", ""))
+}
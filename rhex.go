@@ -0,0 +1,135 @@
+package mckio
+
+import (
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/*
+NewRhex creates an io.Reader delivering the bytes represented by the hex
+dump 'dump', so binary protocol fixtures can be embedded readably in test
+source.  The following formats are tolerated:
+
+- xxd - lines like "00000000: 4865 6c6c 6f0a  Hello.", whose offset and
+text column are ignored.
+
+- hexdump -C - lines like "00000000  48 65 6c 6c 6f 0a  |Hello.|", whose
+offset and text column are ignored.  A "*" line, representing repetitions
+of the preceding line, is expanded up to the offset of the following line.
+
+- Plain hex - like the output of "xxd -p", where whitespace and "0x"
+prefixes separating the digits are ignored.
+
+Lines that are empty or begin with "#" are ignored.  The format is
+determined by the first remaining line.  The offsets of xxd and hexdump -C
+lines must agree with the bytes preceding them.
+
+The reader is an Rchan delivering the bytes as a single message, therefore,
+its behavior can be configured using the behaviors accepted by
+NewChanBehavior, like BehaviorChunkSizer to produce short reads, or
+BehaviorBlockBeforeEachReader to delay them.  It returns an error,
+identifying the line, when the dump can't be parsed.
+*/
+func NewRhex(dump string, behavior interface{}) (rdr Rchan, err error) {
+	data, err := hexDumpDecode(dump)
+	if err != nil {
+		return rdr, err
+	}
+	return fixtureReader(behavior, data), nil
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+var (
+	xxdLine   = regexp.MustCompile(`^([0-9a-fA-F]+):(.*)$`)
+	canonLine = regexp.MustCompile(`^([0-9a-fA-F]{7,})(\s\s([^|]*))?`)
+)
+
+// creates a reader delivering each non-empty element of 'data' as a message
+// followed by io.EOF.
+func fixtureReader(behavior interface{}, data ...[]byte) Rchan {
+	msg := make(chan string, len(data))
+	for _, d := range data {
+		if len(d) > 0 {
+			msg <- string(d)
+		}
+	}
+	close(msg)
+	return NewChanBehavior(msg, behavior)
+}
+
+// converts a hex dump into the bytes it represents.
+func hexDumpDecode(dump string) ([]byte, error) {
+	var data, prev []byte
+	var format *regexp.Regexp
+	repeat := false
+	for i, line := range strings.Split(dump, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if format == nil {
+			format = hexDumpFormat(line)
+		}
+		if line == "*" && format != nil {
+			repeat = true
+			continue
+		}
+		if format == nil {
+			b, err := hexDecode(strings.ReplaceAll(line, "0x", ""))
+			if err != nil {
+				return nil, fmt.Errorf("mckio: hex dump line %d: %w", i+1, err)
+			}
+			data = append(data, b...)
+			continue
+		}
+		m := format.FindStringSubmatch(line)
+		if m == nil {
+			return nil, fmt.Errorf("mckio: hex dump line %d \"%s\" doesn't match the format of the first line", i+1, excerpt(line))
+		}
+		offset, _ := strconv.ParseInt(m[1], 16, 64)
+		for repeat && len(prev) > 0 && int64(len(data)) < offset {
+			data = append(data, prev...)
+		}
+		repeat = false
+		if offset != int64(len(data)) {
+			return nil, fmt.Errorf("mckio: hex dump line %d offset %#x doesn't follow the %#x bytes preceding it", i+1, offset, len(data))
+		}
+		hexPart := m[len(m)-1]
+		if format == xxdLine {
+			// the text column follows two spaces.
+			if end := strings.Index(strings.TrimLeft(hexPart, " "), "  "); end >= 0 {
+				hexPart = strings.TrimLeft(hexPart, " ")[:end]
+			}
+		}
+		b, err := hexDecode(hexPart)
+		if err != nil {
+			return nil, fmt.Errorf("mckio: hex dump line %d: %w", i+1, err)
+		}
+		data = append(data, b...)
+		prev = b
+	}
+	return data, nil
+}
+
+// selects the regular expression parsing the lines of a dump, which is nil
+// for plain hex.
+func hexDumpFormat(line string) *regexp.Regexp {
+	if xxdLine.MatchString(line) {
+		return xxdLine
+	}
+	if canonLine.MatchString(line) && strings.Contains(line, "|") {
+		return canonLine
+	}
+	return nil
+}
+
+// decodes hex digits ignoring the whitespace separating them.
+func hexDecode(s string) ([]byte, error) {
+	return hex.DecodeString(strings.Join(strings.Fields(s), ""))
+}
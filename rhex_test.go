@@ -0,0 +1,69 @@
+package mckio
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RhexXxd(t *testing.T) {
+	assrt := assert.New(t)
+	rdr, err := NewRhex(`
+00000000: 4865 6c6c 6f2c 2077 6f72 6c64 210a 0001  Hello, world!...
+00000010: ff                                       .
+`, nil)
+	assrt.Nil(err)
+	out, err := io.ReadAll(&rdr)
+	assrt.Nil(err)
+	assrt.Equal("Hello, world!\n\x00\x01\xff", string(out))
+}
+func Test_RhexCanonical(t *testing.T) {
+	assrt := assert.New(t)
+	rdr, err := NewRhex(`
+# hexdump -C
+00000000  41 41 41 41 41 41 41 41  41 41 41 41 41 41 41 41  |AAAAAAAAAAAAAAAA|
+*
+00000020  42 0a                                             |B.|
+00000022
+`, nil)
+	assrt.Nil(err)
+	out, err := io.ReadAll(&rdr)
+	assrt.Nil(err)
+	assrt.Equal("AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAB\n", string(out))
+}
+func Test_RhexPlain(t *testing.T) {
+	assrt := assert.New(t)
+	rdr, err := NewRhex("48656c\n6c 6f\n0x0a 0x00", chunkSize(2))
+	assrt.Nil(err)
+	p := make([]byte, 10)
+	n, err := rdr.Read(p)
+	assrt.Nil(err)
+	assrt.Equal("He", string(p[:n]))
+	out, err := io.ReadAll(&rdr)
+	assrt.Nil(err)
+	assrt.Equal("llo\n\x00", string(out))
+}
+func Test_RhexErrors(t *testing.T) {
+	assrt := assert.New(t)
+	_, err := NewRhex("00000000: 4142  AB\n00000003: 43  C", nil)
+	if assrt.NotNil(err) {
+		assrt.Contains(err.Error(), "line 2 offset 0x3 doesn't follow the 0x2 bytes")
+	}
+	_, err = NewRhex("414", nil)
+	if assrt.NotNil(err) {
+		assrt.Contains(err.Error(), "hex dump line 1")
+	}
+	_, err = NewRhex("00000000: 41  A\nzz", nil)
+	if assrt.NotNil(err) {
+		assrt.Contains(err.Error(), "line 2 \"zz\" doesn't match")
+	}
+}
+func Test_RhexEmpty(t *testing.T) {
+	assrt := assert.New(t)
+	rdr, err := NewRhex("", nil)
+	assrt.Nil(err)
+	out, err := io.ReadAll(&rdr)
+	assrt.Nil(err)
+	assrt.Empty(out)
+}
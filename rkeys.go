@@ -0,0 +1,190 @@
+package mckio
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+/*
+Rkeys simulates keystrokes typed on a console, delivering one key per Read,
+so line editing and raw-mode input handling can be tested instead of only
+whole lines.
+
+- A key is a single rune, including control characters like backspace
+(0x08), tab, or Ctrl-C (0x03), or an entire escape sequence, like the
+"\x1b[A" emitted by the up arrow, which a terminal delivers at once.
+
+- When 'p' is shorter than a key, its remaining bytes are returned by the
+following Reads before the next key.
+
+- The reader returns io.EOF once every key was read.
+
+The following behavior of Rkeys can be configured:
+
+- BehaviorBlockBeforeEachReader (optional) - executed before delivering each
+key, simulating the delay between keystrokes.  KeyDelay implements it.
+When undefined - keys are delivered without delay.
+
+- BehaviorClocker (optional) - supplies the Clock KeyDelay sleeps on.  When
+undefined - uses the system clock.
+
+- BehaviorEchoer (optional) - specifies a writer receiving a copy of each
+key read, like a terminal in cooked mode echoing keystrokes.  When
+undefined - no echo occurs.
+
+- BehaviorBlockAtEnder (optional) - executed once after the last key was
+read, before returning io.EOF.  When undefined - io.EOF is returned
+immediately.
+
+Rkeys is not concurrency safe.
+*/
+type Rkeys struct {
+	keys        []string
+	next        int
+	cur         string
+	blockBefore func()
+	blockEnd    func()
+	echo        io.Writer
+	blocked     int32
+}
+
+/*
+KeyDelay implements BehaviorBlockBeforeEachReader by sleeping for its
+duration.  Embed it in a behavior struct to combine it with other behaviors,
+like BehaviorClocker, so Rkeys sleeps on the supplied Clock.
+*/
+type KeyDelay time.Duration
+
+/*
+BehaviorBlockBeforeEachRead sleeps for the delay on the system clock.
+*/
+func (kd KeyDelay) BehaviorBlockBeforeEachRead() {
+	SystemClock().Sleep(time.Duration(kd))
+}
+
+/*
+NewKeystrokes creates an io.Reader delivering the keys typed by 'keys' one
+at a time, whose behavior can be configured using
+BehaviorBlockBeforeEachReader, BehaviorEchoer, BehaviorBlockAtEnder, and
+BehaviorClocker.
+*/
+func NewKeystrokes(keys string, behavior interface{}) (rdr Rkeys) {
+	rdr.keys = splitKeys(keys)
+	if kd, ok := behavior.(keyDelayer); ok {
+		kc := keyDelayClock{delay: kd.keyDelay(), clock: clockOf(behavior)}
+		rdr.blockBefore = kc.BehaviorBlockBeforeEachRead
+	} else if bbr, ok := behavior.(BehaviorBlockBeforeEachReader); ok {
+		rdr.blockBefore = bbr.BehaviorBlockBeforeEachRead
+	}
+	if bbe, ok := behavior.(BehaviorBlockAtEnder); ok {
+		rdr.blockEnd = bbe.BehaviorBlockAtEnd
+	}
+	if be, ok := behavior.(BehaviorEchoer); ok {
+		rdr.echo = be.BehaviorEcho()
+	}
+	return rdr
+}
+
+/*
+Read returns the next key, or the remainder of the current one, conforming
+to io.Reader semantics (https://golang.org/pkg/io/#Reader).
+*/
+func (rk *Rkeys) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if len(rk.cur) == 0 {
+		if rk.next >= len(rk.keys) {
+			if rk.blockEnd != nil {
+				blocking(&rk.blocked, rk.blockEnd)
+				rk.blockEnd = nil
+			}
+			return 0, io.EOF
+		}
+		if rk.blockBefore != nil {
+			blocking(&rk.blocked, rk.blockBefore)
+		}
+		rk.cur = rk.keys[rk.next]
+		rk.next++
+	}
+	n := copy(p, rk.cur)
+	rk.cur = rk.cur[n:]
+	if rk.echo != nil {
+		rk.echo.Write(p[:n])
+	}
+	return n, nil
+}
+
+/*
+Len returns the number of keys not yet delivered, excluding the key
+partially read.
+*/
+func (rk *Rkeys) Len() int {
+	return len(rk.keys) - rk.next
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+func (rk *Rkeys) readBlocked() bool {
+	return atomic.LoadInt32(&rk.blocked) == 1
+}
+
+// implemented by KeyDelay, including when embedded, so Rkeys can sleep on
+// the Clock supplied by the behavior.
+type keyDelayer interface {
+	keyDelay() time.Duration
+}
+
+func (kd KeyDelay) keyDelay() time.Duration {
+	return time.Duration(kd)
+}
+
+type keyDelayClock struct {
+	delay time.Duration
+	clock Clock
+}
+
+func (kc keyDelayClock) BehaviorBlockBeforeEachRead() {
+	kc.clock.Sleep(kc.delay)
+}
+
+// splits keys into individual keystrokes.
+func splitKeys(keys string) (split []string) {
+	for len(keys) > 0 {
+		sz := keyLen(keys)
+		split = append(split, keys[:sz])
+		keys = keys[sz:]
+	}
+	return split
+}
+
+// the length of the keystroke beginning keys: an escape sequence or a rune.
+func keyLen(keys string) int {
+	if keys[0] != ansiEscByte || len(keys) == 1 {
+		_, sz := utf8.DecodeRuneInString(keys)
+		return sz
+	}
+	switch keys[1] {
+	case '[':
+		// CSI ends with its final byte.
+		for i := 2; i < len(keys); i++ {
+			if keys[i] >= 0x40 && keys[i] <= 0x7e {
+				return i + 1
+			}
+		}
+		return len(keys)
+	case 'O':
+		// SS3, like the function keys F1-F4, selects a single character.
+		if len(keys) > 2 {
+			return 3
+		}
+		return 2
+	}
+	// Alt or Meta combined with a key.
+	_, sz := utf8.DecodeRuneInString(keys[1:])
+	return 1 + sz
+}
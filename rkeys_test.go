@@ -0,0 +1,72 @@
+package mckio
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_KeystrokesSimple(t *testing.T) {
+	assrt := assert.New(t)
+	rdr := NewKeystrokes("ab\x08é\t\x1b[A\x1bOP\x1bx\x1b", nil)
+	assrt.Equal(9, rdr.Len())
+	var keys []string
+	p := make([]byte, 16)
+	for {
+		n, err := rdr.Read(p)
+		if err == io.EOF {
+			break
+		}
+		keys = append(keys, string(p[:n]))
+	}
+	assrt.Equal([]string{"a", "b", "\x08", "é", "\t", "\x1b[A", "\x1bOP", "\x1bx", "\x1b"}, keys)
+	assrt.Equal(0, rdr.Len())
+}
+func Test_KeystrokesShortRead(t *testing.T) {
+	assrt := assert.New(t)
+	rdr := NewKeystrokes("\x1b[1;5Cq", nil)
+	p := make([]byte, 4)
+	n, _ := rdr.Read(p)
+	assrt.Equal("\x1b[1;", string(p[:n]))
+	n, _ = rdr.Read(p)
+	assrt.Equal("5C", string(p[:n]))
+	n, _ = rdr.Read(p)
+	assrt.Equal("q", string(p[:n]))
+}
+func Test_KeystrokesDelay(t *testing.T) {
+	assrt := assert.New(t)
+	rdr := NewKeystrokes("ab", KeyDelay(10*time.Millisecond))
+	start := time.Now()
+	all, err := io.ReadAll(&rdr)
+	assrt.Nil(err)
+	assrt.Equal("ab", string(all))
+	assrt.True(time.Since(start) >= 20*time.Millisecond)
+}
+func Test_KeystrokesDelayClock(t *testing.T) {
+	assrt := assert.New(t)
+	fc := NewFakeClock(time.Time{})
+	rdr := NewKeystrokes("ab", struct {
+		KeyDelay
+		*FakeClock
+	}{KeyDelay(time.Second), fc})
+	fc.SetAutoAdvance(&rdr)
+	all, err := io.ReadAll(&rdr)
+	assrt.Nil(err)
+	assrt.Equal("ab", string(all))
+	assrt.Equal(time.Time{}.Add(2*time.Second), fc.Now())
+	waits := fc.Waits()
+	assrt.Len(waits, 2)
+	assrt.Equal("keyDelayClock.BehaviorBlockBeforeEachRead", waits[0].Behavior)
+}
+func Test_KeystrokesEcho(t *testing.T) {
+	assrt := assert.New(t)
+	screen := NewWrecorder()
+	rdr := NewKeystrokes("y\r", echoTo{screen})
+	p := make([]byte, 1)
+	rdr.Read(p)
+	assrt.Equal("y", screen.String())
+	io.ReadAll(&rdr)
+	assrt.Equal("y\r", screen.String())
+}
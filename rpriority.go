@@ -0,0 +1,91 @@
+package mckio
+
+import "io"
+
+/*
+Rpriority converts two channels streaming strings, a priority channel and a
+normal one, into an io.Reader.  Messages from the priority channel preempt
+the normal channel at the next Read boundary, even when a normal message
+has been partially read, so tests can inject out-of-band input, like a
+"quit" command, into a long streaming scenario.
+
+- A Read returns bytes from only one message.
+
+- Residual bytes of a preempted normal message are returned once the
+priority channel has no message available.
+
+- The reader returns io.EOF after both channels are closed and their
+messages consumed.
+
+- Rpriority is not concurrency safe.
+*/
+type Rpriority struct {
+	priority <-chan string
+	normal   <-chan string
+	pCur     string
+	nCur     string
+}
+
+/*
+NewChanPriority creates an io.Reader that prefers messages received from
+'priority' over those received from 'normal'.
+*/
+func NewChanPriority(priority <-chan string, normal <-chan string) (rdr Rpriority) {
+	return Rpriority{priority: priority, normal: normal}
+}
+
+/*
+Read implements an io.Reader based on two channels conforming to
+io.Reader semantics (https://golang.org/pkg/io/#Reader).
+*/
+func (rp *Rpriority) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		// because channel can block - return do nothing request instead
+		// of blocking and then returning nothing.
+		return 0, nil
+	}
+	for {
+		if len(rp.pCur) > 0 {
+			n := copy(p, rp.pCur)
+			rp.pCur = rp.pCur[n:]
+			return n, nil
+		}
+		if rp.priority != nil {
+			select {
+			case msg, ok := <-rp.priority:
+				rp.receivedPriority(msg, ok)
+				continue
+			default:
+			}
+		}
+		if len(rp.nCur) > 0 {
+			n := copy(p, rp.nCur)
+			rp.nCur = rp.nCur[n:]
+			return n, nil
+		}
+		if rp.priority == nil && rp.normal == nil {
+			return 0, io.EOF
+		}
+		select {
+		case msg, ok := <-rp.priority:
+			rp.receivedPriority(msg, ok)
+		case msg, ok := <-rp.normal:
+			if !ok {
+				rp.normal = nil
+			}
+			rp.nCur = msg
+		}
+	}
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+func (rp *Rpriority) receivedPriority(msg string, ok bool) {
+	if !ok {
+		// nil channel is never selected
+		rp.priority = nil
+	}
+	rp.pCur = msg
+}
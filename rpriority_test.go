@@ -0,0 +1,56 @@
+package mckio
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RpriorityPreempts(t *testing.T) {
+	assrt := assert.New(t)
+	priority := make(chan string, 1)
+	normal := make(chan string, 2)
+	rdr := NewChanPriority(priority, normal)
+	normal <- "stream 1 "
+	normal <- "stream 2 "
+	p := make([]byte, 4)
+	read := func() string {
+		sz, err := rdr.Read(p)
+		assrt.Nil(err)
+		return string(p[:sz])
+	}
+	assrt.Equal("stre", read())
+	priority <- "quit"
+	// preempts residual of partially read normal message
+	assrt.Equal("quit", read())
+	assrt.Equal("am 1", read())
+	assrt.Equal(" ", read())
+	close(priority)
+	close(normal)
+	var rest string
+	for {
+		sz, err := rdr.Read(p)
+		rest += string(p[:sz])
+		if err != nil {
+			assrt.IsType(io.EOF, err)
+			break
+		}
+	}
+	assrt.Equal("stream 2 ", rest)
+}
+func Test_RpriorityBlocksOnBoth(t *testing.T) {
+	assrt := assert.New(t)
+	priority := make(chan string)
+	normal := make(chan string)
+	rdr := NewChanPriority(priority, normal)
+	go func() { priority <- "ctrl" }()
+	p := make([]byte, 8)
+	sz, err := rdr.Read(p)
+	assrt.Equal("ctrl", string(p[:sz]))
+	assrt.Nil(err)
+	go func() { normal <- "data" }()
+	sz, err = rdr.Read(p)
+	assrt.Equal("data", string(p[:sz]))
+	assrt.Nil(err)
+}
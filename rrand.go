@@ -0,0 +1,97 @@
+package mckio
+
+import (
+	"io"
+	"math/rand"
+)
+
+/*
+Rrand implements an io.Reader producing a reproducible pseudo-random stream
+of bytes of a given length, so large-input tests and benchmarks don't need
+multi-megabyte fixture files.
+
+- The stream is determined solely by its seed, regardless of the sizes of
+the buffers supplied to Read, so the same seed always produces the same
+bytes.  The bytes are generated as they're read, so a large stream doesn't
+consume memory.
+
+- The reader returns io.EOF once 'size' bytes were read.
+
+The following behavior of Rrand can be configured:
+
+- BehaviorChunkSizer (optional) - limits the number of bytes returned by a
+single Read.  When undefined - Read fills 'p' while bytes remain.
+
+- BehaviorBlockBeforeEachReader (optional) - executed before each Read,
+simulating a slow source.  When undefined - the read immediately executes.
+
+- BehaviorBlockAtEnder (optional) - executed once after the last byte was
+read, before returning io.EOF.  When undefined - io.EOF is returned
+immediately.
+
+Rrand is not concurrency safe.
+*/
+type Rrand struct {
+	rnd         *rand.Rand
+	remain      int64
+	chunk       int
+	blockBefore func()
+	blockEnd    func()
+}
+
+/*
+NewRrand creates an io.Reader producing 'size' pseudo-random bytes
+generated from 'seed', whose behavior can be configured using
+BehaviorChunkSizer, BehaviorBlockBeforeEachReader, and BehaviorBlockAtEnder.
+*/
+func NewRrand(seed int64, size int64, behavior interface{}) (rdr Rrand) {
+	rdr.rnd = rand.New(rand.NewSource(seed))
+	rdr.remain = size
+	if bcs, ok := behavior.(BehaviorChunkSizer); ok {
+		rdr.chunk = bcs.BehaviorChunkSize()
+	}
+	if bbr, ok := behavior.(BehaviorBlockBeforeEachReader); ok {
+		rdr.blockBefore = bbr.BehaviorBlockBeforeEachRead
+	}
+	if bbe, ok := behavior.(BehaviorBlockAtEnder); ok {
+		rdr.blockEnd = bbe.BehaviorBlockAtEnd
+	}
+	return rdr
+}
+
+/*
+Read fills 'p' with the next bytes of the stream conforming to io.Reader
+semantics (https://golang.org/pkg/io/#Reader).
+*/
+func (rr *Rrand) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if rr.remain <= 0 {
+		if rr.blockEnd != nil {
+			rr.blockEnd()
+			rr.blockEnd = nil
+		}
+		return 0, io.EOF
+	}
+	if rr.blockBefore != nil {
+		rr.blockBefore()
+	}
+	if rr.chunk > 0 && len(p) > rr.chunk {
+		p = p[:rr.chunk]
+	}
+	if int64(len(p)) > rr.remain {
+		p = p[:rr.remain]
+	}
+	// Rand.Read produces the same stream regardless of the size of 'p'.
+	n, _ := rr.rnd.Read(p)
+	rr.remain -= int64(n)
+	return n, nil
+}
+
+/*
+Len returns the number of bytes not yet read.
+*/
+func (rr *Rrand) Len() int64 {
+	return rr.remain
+}
@@ -0,0 +1,48 @@
+package mckio
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RrandReproducible(t *testing.T) {
+	assrt := assert.New(t)
+	rdr := NewRrand(42, 100000, nil)
+	whole, err := io.ReadAll(&rdr)
+	assrt.Nil(err)
+	assrt.Len(whole, 100000)
+	assrt.Equal(int64(0), rdr.Len())
+	chunked := NewRrand(42, 100000, chunkSize(7))
+	var buf bytes.Buffer
+	p := make([]byte, 13)
+	for {
+		n, err := chunked.Read(p)
+		if err == io.EOF {
+			break
+		}
+		assrt.True(n <= 7)
+		buf.Write(p[:n])
+	}
+	assrt.Equal(whole, buf.Bytes())
+	other := NewRrand(43, 100000, nil)
+	differ, _ := io.ReadAll(&other)
+	assrt.NotEqual(whole, differ)
+}
+func Test_RrandEmpty(t *testing.T) {
+	assrt := assert.New(t)
+	rdr := NewRrand(1, 0, nil)
+	n, err := rdr.Read(make([]byte, 10))
+	assrt.Equal(0, n)
+	assrt.Equal(io.EOF, err)
+}
+func Benchmark_Rrand(b *testing.B) {
+	p := make([]byte, 32*1024)
+	b.SetBytes(1 << 20)
+	for i := 0; i < b.N; i++ {
+		rdr := NewRrand(int64(i), 1<<20, nil)
+		io.CopyBuffer(io.Discard, &rdr, p)
+	}
+}
@@ -0,0 +1,146 @@
+package mckio
+
+import (
+	"fmt"
+	"io"
+)
+
+/*
+Rturns simulates stdin shared by two scripted sources, like an "operator"
+typing and an "automation" feed piping commands, interleaving their
+elements on a schedule, so programs multiplexing human and piped input can
+be tested for correct turn-taking.
+
+- The schedule is a string of the letters 'A' and 'B', each delivering the
+next element of source A or B respectively, like "AABA".  The schedule
+repeats until both sources are exhausted, skipping letters naming an
+exhausted source.  Once the schedule names only exhausted sources, the
+remaining source delivers its elements.  An empty schedule alternates the
+sources, starting with A.
+
+- Each Read returns at most one element, including its delimiter, so the
+boundary between turns is observable.  When 'p' is shorter than the
+element, its remaining bytes are returned by the following Reads before
+the next turn.
+
+- Turns reports the sources of the elements delivered so far, like "ABA",
+to verify the program's responses against the turn that prompted them.
+
+- The reader returns io.EOF once both sources are exhausted.
+
+The following behavior of Rturns can be configured:
+
+- BehaviorDelimer (optional) - specifies the delimiter concatenated to
+each element.  When undefined - no concatenation occurs.
+
+- BehaviorBlockBeforeEachReader (optional) - executed before delivering
+each turn, simulating the pause between them.  When undefined - turns are
+delivered without delay.
+
+- BehaviorBlockAtEnder (optional) - executed once after both sources are
+exhausted, before returning io.EOF.  When undefined - io.EOF is returned
+immediately.
+
+Rturns is not concurrency safe.
+*/
+type Rturns struct {
+	src         [2][]string
+	next        [2]int
+	schedule    string
+	step        int
+	cur         string
+	turns       []byte
+	delim       string
+	blockBefore func()
+	blockEnd    func()
+}
+
+/*
+NewTurns creates an io.Reader interleaving the elements of sources 'a' and
+'b' according to 'schedule', whose behavior can be configured using
+BehaviorDelimer, BehaviorBlockBeforeEachReader, and BehaviorBlockAtEnder.
+Panics if 'schedule' contains a letter other than 'A' or 'B'.
+*/
+func NewTurns(a, b []string, schedule string, behavior interface{}) (rdr Rturns) {
+	for _, c := range schedule {
+		if c != 'A' && c != 'B' {
+			panic(fmt.Sprintf("mckio: Rturns schedule letter %q isn't 'A' or 'B'", c))
+		}
+	}
+	if schedule == "" {
+		schedule = "AB"
+	}
+	rdr.src = [2][]string{a, b}
+	rdr.schedule = schedule
+	if bd, ok := behavior.(BehaviorDelimer); ok {
+		rdr.delim = string(bd.BehaviorDelim())
+	}
+	if bbr, ok := behavior.(BehaviorBlockBeforeEachReader); ok {
+		rdr.blockBefore = bbr.BehaviorBlockBeforeEachRead
+	}
+	if bbe, ok := behavior.(BehaviorBlockAtEnder); ok {
+		rdr.blockEnd = bbe.BehaviorBlockAtEnd
+	}
+	return rdr
+}
+
+/*
+Read returns the next turn's element, or the remainder of the current one,
+conforming to io.Reader semantics (https://golang.org/pkg/io/#Reader).
+*/
+func (rt *Rturns) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if len(rt.cur) == 0 {
+		src, ok := rt.nextTurn()
+		if !ok {
+			if rt.blockEnd != nil {
+				rt.blockEnd()
+				rt.blockEnd = nil
+			}
+			return 0, io.EOF
+		}
+		if rt.blockBefore != nil {
+			rt.blockBefore()
+		}
+		rt.cur = rt.src[src][rt.next[src]] + rt.delim
+		rt.next[src]++
+		rt.turns = append(rt.turns, 'A'+byte(src))
+	}
+	n := copy(p, rt.cur)
+	rt.cur = rt.cur[n:]
+	return n, nil
+}
+
+/*
+Turns returns the sources of the elements delivered so far, in order, as a
+string of the letters 'A' and 'B'.
+*/
+func (rt *Rturns) Turns() string {
+	return string(rt.turns)
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+// advances the schedule to the next letter naming a source with elements
+// remaining.  once a full pass of the schedule names none, the source with
+// elements remaining delivers them.
+func (rt *Rturns) nextTurn() (src int, ok bool) {
+	if rt.next[0] >= len(rt.src[0]) && rt.next[1] >= len(rt.src[1]) {
+		return 0, false
+	}
+	for range rt.schedule {
+		src = int(rt.schedule[rt.step%len(rt.schedule)] - 'A')
+		rt.step++
+		if rt.next[src] < len(rt.src[src]) {
+			return src, true
+		}
+	}
+	if rt.next[0] < len(rt.src[0]) {
+		return 0, true
+	}
+	return 1, true
+}
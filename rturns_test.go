@@ -0,0 +1,58 @@
+package mckio
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TurnsSchedule(t *testing.T) {
+	assrt := assert.New(t)
+	rdr := NewTurns([]string{"o1", "o2"}, []string{"a1", "a2", "a3"}, "ABB", delimAdd{})
+	var got []string
+	p := make([]byte, 10)
+	for {
+		n, err := rdr.Read(p)
+		if err == io.EOF {
+			break
+		}
+		got = append(got, string(p[:n]))
+	}
+	assrt.Equal([]string{"o1\n", "a1\n", "a2\n", "o2\n", "a3\n"}, got)
+	assrt.Equal("ABBAB", rdr.Turns())
+}
+func Test_TurnsDefaultSchedule(t *testing.T) {
+	assrt := assert.New(t)
+	rdr := NewTurns([]string{"o1", "o2", "o3"}, []string{"a1"}, "", nil)
+	out, err := io.ReadAll(&rdr)
+	assrt.Nil(err)
+	assrt.Equal("o1a1o2o3", string(out))
+	assrt.Equal("ABAA", rdr.Turns())
+}
+func Test_TurnsShortRead(t *testing.T) {
+	assrt := assert.New(t)
+	rdr := NewTurns([]string{"abc"}, []string{"d"}, "BA", nil)
+	p := make([]byte, 2)
+	n, _ := rdr.Read(p)
+	assrt.Equal("d", string(p[:n]))
+	n, _ = rdr.Read(p)
+	assrt.Equal("ab", string(p[:n]))
+	n, _ = rdr.Read(p)
+	assrt.Equal("c", string(p[:n]))
+	assrt.Equal("BA", rdr.Turns())
+	_, err := rdr.Read(p)
+	assrt.Equal(io.EOF, err)
+}
+func Test_TurnsSchedulePanics(t *testing.T) {
+	assrt := assert.New(t)
+	assrt.Panics(func() { NewTurns(nil, nil, "AC", nil) })
+}
+func Test_TurnsScheduleNamesOneSource(t *testing.T) {
+	assrt := assert.New(t)
+	rdr := NewTurns([]string{"a1"}, []string{"b1", "b2"}, "A", nil)
+	out, err := io.ReadAll(&rdr)
+	assrt.Nil(err)
+	assrt.Equal("a1b1b2", string(out))
+	assrt.Equal("ABB", rdr.Turns())
+}
@@ -0,0 +1,53 @@
+package mckio
+
+import "io"
+
+/*
+RWC composes any reader and writer, such as the mocks offered by mckio,
+into an io.ReadWriteCloser.  Protocol handlers requiring one, like JSON-RPC
+over stdio, can therefore be tested entirely with mckio primitives.
+
+- Read and Write delegate to the composed reader and writer.
+
+- Close calls the function supplied to NewRWC on every call, so it can
+count or reject repeated closes, like Wcloser.
+
+- RWC is as concurrency safe as the composed reader and writer.
+*/
+type RWC struct {
+	r       io.Reader
+	w       io.Writer
+	onClose func() error
+}
+
+/*
+NewRWC creates an io.ReadWriteCloser reading from r, writing to w, and
+calling onClose when closed.  A nil onClose makes Close a no-op.
+*/
+func NewRWC(r io.Reader, w io.Writer, onClose func() error) RWC {
+	return RWC{r: r, w: w, onClose: onClose}
+}
+
+/*
+Read reads from the composed reader.
+*/
+func (rwc RWC) Read(p []byte) (int, error) {
+	return rwc.r.Read(p)
+}
+
+/*
+Write writes to the composed writer.
+*/
+func (rwc RWC) Write(p []byte) (int, error) {
+	return rwc.w.Write(p)
+}
+
+/*
+Close calls the onClose function supplied to NewRWC, returning its error.
+*/
+func (rwc RWC) Close() error {
+	if rwc.onClose == nil {
+		return nil
+	}
+	return rwc.onClose()
+}
@@ -0,0 +1,37 @@
+package mckio
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RWCJSON(t *testing.T) {
+	assrt := assert.New(t)
+	ws := NewWstrings(nil)
+	rdr := NewNonBlockNoDelim([]string{`{"id":1,"method":"ping"}`})
+	var closes int
+	var rwc io.ReadWriteCloser = NewRWC(&rdr, ws, func() error {
+		closes++
+		return nil
+	})
+	var req map[string]interface{}
+	assrt.Nil(json.NewDecoder(rwc).Decode(&req))
+	assrt.Nil(json.NewEncoder(rwc).Encode(map[string]interface{}{"id": req["id"], "result": "pong"}))
+	assrt.Nil(rwc.Close())
+	assrt.Equal([]string{`{"id":1,"result":"pong"}`}, ws.Lines())
+	assrt.Equal(1, closes)
+}
+func Test_RWCClose(t *testing.T) {
+	assrt := assert.New(t)
+	assrt.Nil(NewRWC(nil, nil, nil).Close())
+	wc := NewWcloser(nil, nil)
+	rwc := NewRWC(nil, wc, wc.Close)
+	assrt.Nil(rwc.Close())
+	assrt.Equal(os.ErrClosed, rwc.Close())
+	_, err := rwc.Write([]byte("msg"))
+	assrt.Equal(os.ErrClosed, err)
+}
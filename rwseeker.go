@@ -0,0 +1,186 @@
+package mckio
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+/*
+RWSeeker implements io.ReadWriteSeeker over a single in-memory region of
+bytes, behaving like a file, for consumers such as database-file-like code
+that require all three verbs from a single handle.  Writes overwrite or
+extend the region at the current offset, zero filling any gap created by
+seeking beyond its end.
+
+The following behavior of RWSeeker can be configured and applies to
+each operation:
+
+- BehaviorBlockBeforeEachOper (optional) - specifies an implementation
+blocking before every Read, Write, and Seek, simulating latency.  When
+undefined - operations immediately execute.
+
+- BehaviorErrInjector (optional) - specifies the error, if any, returned
+by a specific call of an operation instead of performing it.  When
+undefined - operations never fail, except for invalid seeks and io.EOF.
+
+- Stats reports the number of calls to each operation and the bytes
+transferred.
+
+- RWSeeker is concurrency safe.
+*/
+type RWSeeker struct {
+	mu     sync.Mutex
+	region []byte
+	off    int64
+	block  func(op string)
+	inject func(op string, call int) error
+	stats  RWSeekerStats
+}
+
+/*
+Operation names supplied to behaviors of mocks implementing several
+operations.
+*/
+const (
+	OpRead  = "Read"
+	OpWrite = "Write"
+	OpSeek  = "Seek"
+)
+
+/*
+BehaviorBlockBeforeEachOper provides a blocking mechanism that's executed
+at the start of every operation, identified by its name, like OpRead.
+*/
+type BehaviorBlockBeforeEachOper interface {
+	BehaviorBlockBeforeEachOp(op string)
+}
+
+/*
+BehaviorErrInjector returns the error injected into a specific call of an
+operation, identified by its name and the call's ordinal starting at 1.
+A nil error performs the operation normally.
+*/
+type BehaviorErrInjector interface {
+	BehaviorErrInject(op string, call int) error
+}
+
+/*
+RWSeekerStats reports the activity of an RWSeeker.  Call counts include
+calls that failed.
+*/
+type RWSeekerStats struct {
+	Reads        int
+	Writes       int
+	Seeks        int
+	BytesRead    int64
+	BytesWritten int64
+}
+
+/*
+NewRWSeeker creates an RWSeeker whose region initially contains a copy of
+'initial' and whose offset is 0.
+*/
+func NewRWSeeker(initial []byte, behavior interface{}) *RWSeeker {
+	rws := &RWSeeker{region: append([]byte(nil), initial...)}
+	rws.block = func(string) {}
+	if bk, ok := behavior.(BehaviorBlockBeforeEachOper); ok {
+		rws.block = bk.BehaviorBlockBeforeEachOp
+	}
+	rws.inject = func(string, int) error { return nil }
+	if ei, ok := behavior.(BehaviorErrInjector); ok {
+		rws.inject = ei.BehaviorErrInject
+	}
+	return rws
+}
+
+/*
+Read implements an io.Reader over the region starting at the current offset.
+*/
+func (rws *RWSeeker) Read(p []byte) (int, error) {
+	rws.block(OpRead)
+	rws.mu.Lock()
+	defer rws.mu.Unlock()
+	rws.stats.Reads++
+	if err := rws.inject(OpRead, rws.stats.Reads); err != nil {
+		return 0, err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if rws.off >= int64(len(rws.region)) {
+		return 0, io.EOF
+	}
+	n := copy(p, rws.region[rws.off:])
+	rws.off += int64(n)
+	rws.stats.BytesRead += int64(n)
+	return n, nil
+}
+
+/*
+Write implements an io.Writer over the region starting at the current offset.
+*/
+func (rws *RWSeeker) Write(p []byte) (int, error) {
+	rws.block(OpWrite)
+	rws.mu.Lock()
+	defer rws.mu.Unlock()
+	rws.stats.Writes++
+	if err := rws.inject(OpWrite, rws.stats.Writes); err != nil {
+		return 0, err
+	}
+	if end := rws.off + int64(len(p)); end > int64(len(rws.region)) {
+		rws.region = append(rws.region, make([]byte, end-int64(len(rws.region)))...)
+	}
+	n := copy(rws.region[rws.off:], p)
+	rws.off += int64(n)
+	rws.stats.BytesWritten += int64(n)
+	return n, nil
+}
+
+/*
+Seek implements an io.Seeker.  Seeking beyond the end of the region is
+permitted, while seeking before its start returns an error.
+*/
+func (rws *RWSeeker) Seek(offset int64, whence int) (int64, error) {
+	rws.block(OpSeek)
+	rws.mu.Lock()
+	defer rws.mu.Unlock()
+	rws.stats.Seeks++
+	if err := rws.inject(OpSeek, rws.stats.Seeks); err != nil {
+		return 0, err
+	}
+	var abs int64
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = rws.off + offset
+	case io.SeekEnd:
+		abs = int64(len(rws.region)) + offset
+	default:
+		return 0, errors.New("mckio.RWSeeker.Seek: invalid whence")
+	}
+	if abs < 0 {
+		return 0, errors.New("mckio.RWSeeker.Seek: negative position")
+	}
+	rws.off = abs
+	return abs, nil
+}
+
+/*
+Bytes returns a copy of the region.
+*/
+func (rws *RWSeeker) Bytes() []byte {
+	rws.mu.Lock()
+	defer rws.mu.Unlock()
+	return append([]byte(nil), rws.region...)
+}
+
+/*
+Stats reports the calls to each operation and the bytes transferred.
+*/
+func (rws *RWSeeker) Stats() RWSeekerStats {
+	rws.mu.Lock()
+	defer rws.mu.Unlock()
+	return rws.stats
+}
@@ -0,0 +1,65 @@
+package mckio
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RWSeekerFileLike(t *testing.T) {
+	assrt := assert.New(t)
+	rws := NewRWSeeker([]byte("header|body"), nil)
+	p := make([]byte, 6)
+	sz, err := rws.Read(p)
+	assrt.Equal("header", string(p[:sz]))
+	assrt.Nil(err)
+	pos, err := rws.Seek(1, io.SeekCurrent)
+	assrt.Equal(int64(7), pos)
+	rws.Write([]byte("BODY"))
+	// extend beyond end with a gap
+	pos, _ = rws.Seek(2, io.SeekEnd)
+	assrt.Equal(int64(13), pos)
+	rws.Write([]byte("!"))
+	assrt.Equal([]byte("header|BODY\x00\x00!"), rws.Bytes())
+	rws.Seek(-3, io.SeekEnd)
+	rest, err := io.ReadAll(rws)
+	assrt.Nil(err)
+	assrt.Equal([]byte("\x00\x00!"), rest)
+	_, err = rws.Seek(-1, io.SeekStart)
+	assrt.NotNil(err)
+	assrt.Equal(RWSeekerStats{Reads: 3, Writes: 2, Seeks: 4, BytesRead: 9, BytesWritten: 5}, rws.Stats())
+}
+func Test_RWSeekerBehaviors(t *testing.T) {
+	assrt := assert.New(t)
+	bhv := &rwsBehavior{}
+	rws := NewRWSeeker([]byte("data"), bhv)
+	errDisk := errors.New("disk failure")
+	bhv.fail = map[string]int{OpWrite: 2}
+	bhv.err = errDisk
+	_, err := rws.Write([]byte("a"))
+	assrt.Nil(err)
+	_, err = rws.Write([]byte("b"))
+	assrt.Equal(errDisk, err)
+	rws.Seek(0, io.SeekStart)
+	rws.Read(make([]byte, 1))
+	assrt.Equal([]string{OpWrite, OpWrite, OpSeek, OpRead}, bhv.ops)
+	assrt.Equal([]byte("aata"), rws.Bytes())
+}
+
+type rwsBehavior struct {
+	ops  []string
+	fail map[string]int
+	err  error
+}
+
+func (rb *rwsBehavior) BehaviorBlockBeforeEachOp(op string) {
+	rb.ops = append(rb.ops, op)
+}
+func (rb *rwsBehavior) BehaviorErrInject(op string, call int) error {
+	if rb.fail[op] == call {
+		return rb.err
+	}
+	return nil
+}
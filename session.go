@@ -0,0 +1,259 @@
+package mckio
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+/*
+SessionRecorder records an interactive session, the bytes read from stdin
+and written to stdout and stderr along with their timing, so a regression
+test can be generated from a single manual run and replayed by
+SessionReplay.
+
+- Wrap the streams of the session using Reader and Writer, identifying each
+by a name, like "stdin" or "stdout".
+
+- Save writes the recording as a transcript file of JSON lines, one event
+per line.  LoadSession reads it.
+
+The following behavior of SessionRecorder can be configured:
+
+- BehaviorClocker (optional) - supplies the Clock stamping each event.
+When undefined - uses the system clock.
+
+SessionRecorder is concurrency safe.
+*/
+type SessionRecorder struct {
+	mu     sync.Mutex
+	clock  Clock
+	start  time.Time
+	events []SessionEvent
+}
+
+/*
+SessionEvent is a chunk of data read or written by a stream of a session.
+At is the time elapsed since the recording started.
+*/
+type SessionEvent struct {
+	Stream string        `json:"stream"`
+	At     time.Duration `json:"at"`
+	Data   []byte        `json:"data"`
+}
+
+/*
+NewSessionRecorder starts a recording whose clock can be configured using
+BehaviorClocker.
+*/
+func NewSessionRecorder(behavior interface{}) *SessionRecorder {
+	sr := &SessionRecorder{clock: clockOf(behavior)}
+	sr.start = sr.clock.Now()
+	return sr
+}
+
+/*
+Reader wraps 'r' recording the data read from it as events of 'stream'.
+*/
+func (sr *SessionRecorder) Reader(stream string, r io.Reader) io.Reader {
+	return sessionReader{sr: sr, stream: stream, r: r}
+}
+
+/*
+Writer wraps 'w' recording the data written to it as events of 'stream'.
+*/
+func (sr *SessionRecorder) Writer(stream string, w io.Writer) io.Writer {
+	return sessionWriter{sr: sr, stream: stream, w: w}
+}
+
+/*
+Events returns the events recorded so far in the order they occurred.
+*/
+func (sr *SessionRecorder) Events() []SessionEvent {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	return append([]SessionEvent(nil), sr.events...)
+}
+
+/*
+Save writes the events recorded so far to 'w' as JSON lines.
+*/
+func (sr *SessionRecorder) Save(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, ev := range sr.Events() {
+		if err := enc.Encode(ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+LoadSession reads the events of a transcript written by Save.
+*/
+func LoadSession(r io.Reader) (events []SessionEvent, err error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	for {
+		var ev SessionEvent
+		if err := dec.Decode(&ev); err == io.EOF {
+			return events, nil
+		} else if err != nil {
+			return events, err
+		}
+		events = append(events, ev)
+	}
+}
+
+/*
+SessionReplay replays a recorded session, supplying the recorded input of a
+stream to the code under test and the recorded output expected of it.
+
+- Reader delivers each recorded chunk of a stream by a single Read, when
+'p' is large enough, then returns io.EOF.
+
+- Output returns the data recorded for a stream, for comparison with the
+output of the code under test, like that captured by a Wrecorder.
+
+The following behavior of SessionReplay can be configured:
+
+- BehaviorPacer (optional) - specifies whether Readers reproduce the
+recorded timing, delaying each chunk until its time elapsed since the
+stream's first Read matches the recording.  When undefined - chunks are
+delivered without delay.
+
+- BehaviorClocker (optional) - supplies the Clock measuring the delays
+reproduced by BehaviorPacer.  When undefined - uses the system clock.
+
+SessionReplay is concurrency safe, although each Reader isn't.
+*/
+type SessionReplay struct {
+	events []SessionEvent
+	pace   bool
+	clock  Clock
+}
+
+/*
+BehaviorPacer specifies whether a mock reproduces recorded timing.
+*/
+type BehaviorPacer interface {
+	BehaviorPace() bool
+}
+
+/*
+NewSessionReplay creates a replay of 'events', whose behavior can be
+configured using BehaviorPacer and BehaviorClocker.
+*/
+func NewSessionReplay(events []SessionEvent, behavior interface{}) *SessionReplay {
+	rp := &SessionReplay{events: events, clock: clockOf(behavior)}
+	if bp, ok := behavior.(BehaviorPacer); ok {
+		rp.pace = bp.BehaviorPace()
+	}
+	return rp
+}
+
+/*
+Reader returns an io.Reader delivering the data recorded for 'stream'.
+*/
+func (rp *SessionReplay) Reader(stream string) io.Reader {
+	return &replayReader{events: rp.streamEvents(stream), pace: rp.pace, clock: rp.clock}
+}
+
+/*
+Output returns the data recorded for 'stream' concatenated.
+*/
+func (rp *SessionReplay) Output(stream string) string {
+	var out []byte
+	for _, ev := range rp.streamEvents(stream) {
+		out = append(out, ev.Data...)
+	}
+	return string(out)
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+func (sr *SessionRecorder) record(stream string, p []byte) {
+	if len(p) == 0 {
+		return
+	}
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.events = append(sr.events, SessionEvent{
+		Stream: stream,
+		At:     sr.clock.Now().Sub(sr.start),
+		Data:   append([]byte(nil), p...),
+	})
+}
+
+type sessionReader struct {
+	sr     *SessionRecorder
+	stream string
+	r      io.Reader
+}
+
+func (sr sessionReader) Read(p []byte) (int, error) {
+	n, err := sr.r.Read(p)
+	sr.sr.record(sr.stream, p[:n])
+	return n, err
+}
+
+type sessionWriter struct {
+	sr     *SessionRecorder
+	stream string
+	w      io.Writer
+}
+
+func (sw sessionWriter) Write(p []byte) (int, error) {
+	n, err := sw.w.Write(p)
+	sw.sr.record(sw.stream, p[:n])
+	return n, err
+}
+func (rp *SessionReplay) streamEvents(stream string) (events []SessionEvent) {
+	for _, ev := range rp.events {
+		if ev.Stream == stream {
+			events = append(events, ev)
+		}
+	}
+	return events
+}
+
+type replayReader struct {
+	events []SessionEvent
+	cur    []byte
+	pace   bool
+	clock  Clock
+	start  time.Time
+	origin time.Duration
+}
+
+func (rr *replayReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if len(rr.cur) == 0 {
+		if len(rr.events) == 0 {
+			return 0, io.EOF
+		}
+		ev := rr.events[0]
+		rr.events = rr.events[1:]
+		if rr.pace {
+			rr.wait(ev.At)
+		}
+		rr.cur = ev.Data
+	}
+	n := copy(p, rr.cur)
+	rr.cur = rr.cur[n:]
+	return n, nil
+}
+
+// delays until the time elapsed since the first read reflects 'at'.
+func (rr *replayReader) wait(at time.Duration) {
+	if rr.start.IsZero() {
+		rr.start, rr.origin = rr.clock.Now(), at
+		return
+	}
+	rr.clock.Sleep(rr.start.Add(at - rr.origin).Sub(rr.clock.Now()))
+}
@@ -0,0 +1,59 @@
+package mckio
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// interactive function under test.
+func echoNames(in io.Reader, out io.Writer) {
+	scn := bufio.NewScanner(in)
+	for fmt.Fprint(out, "name? "); scn.Scan(); fmt.Fprint(out, "name? ") {
+		fmt.Fprintf(out, "hello %s\n", scn.Text())
+	}
+}
+func Test_SessionRecordReplay(t *testing.T) {
+	assrt := assert.New(t)
+	// the manual run
+	clock := &stepClock{at: time.Unix(0, 0), step: 10 * time.Millisecond}
+	sr := NewSessionRecorder(clock)
+	dc := NewDuplexConsole([]string{"alice", "bob"})
+	echoNames(sr.Reader("stdin", dc.Reader()), sr.Writer("stdout", dc.Writer()))
+	var file bytes.Buffer
+	assrt.Nil(sr.Save(&file))
+	events, err := LoadSession(&file)
+	assrt.Nil(err)
+	assrt.Equal(sr.Events(), events)
+	assrt.Equal(SessionEvent{Stream: "stdout", At: 10 * time.Millisecond, Data: []byte("name? ")}, events[0])
+	// the regression test
+	rp := NewSessionReplay(events, nil)
+	out := NewWrecorder()
+	echoNames(rp.Reader("stdin"), out)
+	assrt.Equal(rp.Output("stdout"), out.String())
+	assrt.Equal("name? hello alice\nname? hello bob\nname? ", out.String())
+}
+
+type pace bool
+
+func (pc pace) BehaviorPace() bool {
+	return bool(pc)
+}
+func Test_SessionReplayPace(t *testing.T) {
+	assrt := assert.New(t)
+	rp := NewSessionReplay([]SessionEvent{
+		{Stream: "stdin", At: time.Second, Data: []byte("a")},
+		{Stream: "stdin", At: time.Second + 20*time.Millisecond, Data: []byte("b")},
+	}, pace(true))
+	start := time.Now()
+	all, err := io.ReadAll(rp.Reader("stdin"))
+	assrt.Nil(err)
+	assrt.Equal("ab", string(all))
+	assrt.True(time.Since(start) >= 20*time.Millisecond)
+	assrt.True(time.Since(start) < time.Second)
+}
@@ -0,0 +1,153 @@
+package mckio
+
+import (
+	"io"
+	"os"
+	"sync"
+	"testing"
+)
+
+/*
+WithStdio executes fn while os.Stdin, os.Stdout, and os.Stderr are replaced,
+covering the most common end-to-end CLI test in a single call.
+
+- os.Stdin is replaced by a pipe delivering the content of 'in', followed
+by end of file.  A nil 'in' delivers only end of file.  Once WithStdio
+returns, 'in' is no longer read, however, a Read of 'in' blocked at that
+moment keeps the goroutine copying it until the Read returns.
+
+- os.Stdout and os.Stderr are captured using FileCaptureStart and their
+content returned once fn completes.
+
+The original standard streams are always restored before WithStdio
+returns, even when fn panics.  Failure to establish the replacements fails
+the test.
+
+WithStdio isn't concurrency safe, as it replaces package-level variables.
+Don't call it from tests running in parallel.
+*/
+func WithStdio(t testing.TB, in io.Reader, fn func()) (stdout string, stderr string) {
+	t.Helper()
+	stdin, stop, err := stdinPipe(in)
+	if err != nil {
+		t.Fatalf("mckio: unable to replace os.Stdin: %v", err)
+	}
+	origIn := os.Stdin
+	os.Stdin = stdin
+	defer func() {
+		os.Stdin = origIn
+		stop()
+	}()
+	outCap, outEnd, err := FileCaptureStart(&os.Stdout)
+	if err != nil {
+		t.Fatalf("mckio: unable to capture os.Stdout: %v", err)
+	}
+	outEnded := false
+	defer func() {
+		if !outEnded {
+			outEnd()
+		}
+	}()
+	errCap, errEnd, err := FileCaptureStart(&os.Stderr)
+	if err != nil {
+		t.Fatalf("mckio: unable to capture os.Stderr: %v", err)
+	}
+	errEnded := false
+	defer func() {
+		if !errEnded {
+			errEnd()
+		}
+	}()
+	fn()
+	errEnd()
+	errEnded = true
+	outEnd()
+	outEnded = true
+	return <-outCap, <-errCap
+}
+
+/*
+StdinSwapStart replaces os.Stdin with a pipe delivering 'lines', followed by
+end of file, until restore is called.  Unlike Rstrings, which isn't an
+*os.File, it covers code reading the package-level variable directly, like
+bufio.NewScanner(os.Stdin).  Its behavior can be configured:
+
+- BehaviorDelimer (optional) - specifies the delimiter terminating each
+line.  When undefined - a newline.
+
+restore reverts os.Stdin to its original value.  Calling it more than once
+is harmless.  StdinSwapStart isn't concurrency safe, as it replaces a
+package-level variable.
+*/
+func StdinSwapStart(lines []string, behavior interface{}) (restore func(), err error) {
+	var delim interface{} = harnessDelim{}
+	if bd, ok := behavior.(BehaviorDelimer); ok {
+		delim = bd
+	}
+	in := NewRstrings(lines, delim)
+	stdin, stop, err := stdinPipe(&in)
+	if err != nil {
+		return nil, err
+	}
+	orig := os.Stdin
+	os.Stdin = stdin
+	var once sync.Once
+	restore = func() {
+		once.Do(func() {
+			os.Stdin = orig
+			stop()
+		})
+	}
+	return restore, nil
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+// creates a pipe whose read end delivers the content of 'in' followed by
+// end of file.  stop closes the read end, which fails a write blocked on
+// the pipe, and ends the copy before its next Read of 'in'.
+func stdinPipe(in io.Reader) (*os.File, func(), error) {
+	rdr, wrt, err := os.Pipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	done := make(chan struct{})
+	go copyStdin(wrt, in, done)
+	stop := func() {
+		close(done)
+		rdr.Close()
+	}
+	return rdr, stop, nil
+}
+
+// writes 'in' to the pipe until end of file, a failed write, or stop.
+func copyStdin(wrt *os.File, in io.Reader, done <-chan struct{}) {
+	defer wrt.Close()
+	if in == nil {
+		return
+	}
+	buf := make([]byte, 32*1024)
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		sz, err := in.Read(buf)
+		if sz > 0 {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if _, werr := wrt.Write(buf[:sz]); werr != nil {
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package mckio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WithStdio(t *testing.T) {
+	assrt := assert.New(t)
+	origIn, origOut, origErr := os.Stdin, os.Stdout, os.Stderr
+	in := NewRstrings([]string{"alice", "bob"}, delimAdd{})
+	stdout, stderr := WithStdio(t, &in, func() {
+		scn := bufio.NewScanner(os.Stdin)
+		for scn.Scan() {
+			fmt.Printf("hello %s\n", scn.Text())
+		}
+		fmt.Fprint(os.Stderr, "done")
+	})
+	assrt.Equal("hello alice\nhello bob\n", stdout)
+	assrt.Equal("done", stderr)
+	assrt.Equal(origIn, os.Stdin)
+	assrt.Equal(origOut, os.Stdout)
+	assrt.Equal(origErr, os.Stderr)
+}
+func Test_WithStdioRestoresAfterPanic(t *testing.T) {
+	assrt := assert.New(t)
+	origIn, origOut, origErr := os.Stdin, os.Stdout, os.Stderr
+	assrt.Panics(func() {
+		WithStdio(t, strings.NewReader("unread input"), func() {
+			fmt.Print("partial")
+			panic("program failure")
+		})
+	})
+	assrt.Equal(origIn, os.Stdin)
+	assrt.Equal(origOut, os.Stdout)
+	assrt.Equal(origErr, os.Stderr)
+}
+func Test_WithStdioBlockedIn(t *testing.T) {
+	assrt := assert.New(t)
+	before := goroutines("mckio.copyStdin(")
+	in := &blockedIn{release: make(chan struct{})}
+	stdout, _ := WithStdio(t, in, func() { fmt.Print("ignores stdin") })
+	assrt.Equal("ignores stdin", stdout)
+	// the copy remains blocked reading 'in' until it returns
+	close(in.release)
+	assrt.True(awaitGoroutines("mckio.copyStdin(", before))
+	assrt.Equal(int32(2), atomic.LoadInt32(&in.reads))
+}
+
+// delivers "input" then blocks until released.
+type blockedIn struct {
+	reads   int32
+	release chan struct{}
+}
+
+func (bi *blockedIn) Read(p []byte) (int, error) {
+	if atomic.AddInt32(&bi.reads, 1) > 1 {
+		<-bi.release
+	}
+	return copy(p, "input"), nil
+}
+func Test_StdinSwapStart(t *testing.T) {
+	assrt := assert.New(t)
+	origIn := os.Stdin
+	restore, err := StdinSwapStart([]string{"alice", "bob"}, nil)
+	assrt.Nil(err)
+	defer restore()
+	var names []string
+	scn := bufio.NewScanner(os.Stdin)
+	for scn.Scan() {
+		names = append(names, scn.Text())
+	}
+	assrt.Equal([]string{"alice", "bob"}, names)
+	restore()
+	restore()
+	assrt.Equal(origIn, os.Stdin)
+}
+func Test_StdinSwapStartDelim(t *testing.T) {
+	assrt := assert.New(t)
+	restore, err := StdinSwapStart([]string{"a", "b"}, wsDelim("\x00"))
+	assrt.Nil(err)
+	defer restore()
+	all, err := io.ReadAll(os.Stdin)
+	assrt.Nil(err)
+	assrt.Equal("a\x00b\x00", string(all))
+}
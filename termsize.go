@@ -0,0 +1,84 @@
+package mckio
+
+import (
+	"os"
+	"sync"
+)
+
+/*
+TermSize simulates the dimensions of a terminal, reporting configurable
+columns and rows to the code under test and notifying it of resizes, so
+layout logic, like progress bars and TUIs, can be verified at several
+widths.
+
+- Size mirrors the result of golang.org/x/term.GetSize, so code under test
+can accept it as a function in lieu of querying a real terminal.
+
+- Notify mirrors signal.Notify for WindowChange.  Resize delivers
+WindowChange to every registered channel without blocking, like
+signal.Notify, so provide channels buffered to at least one signal.
+
+TermSize is concurrency safe.
+*/
+type TermSize struct {
+	mu   sync.Mutex
+	cols int
+	rows int
+	subs []chan<- os.Signal
+}
+
+/*
+NewTermSize creates a terminal 'cols' wide and 'rows' high.
+*/
+func NewTermSize(cols, rows int) *TermSize {
+	return &TermSize{cols: cols, rows: rows}
+}
+
+/*
+Size returns the current width and height.  It never fails.
+*/
+func (ts *TermSize) Size() (width, height int, err error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return ts.cols, ts.rows, nil
+}
+
+/*
+Resize changes the dimensions, then delivers WindowChange to the channels
+registered by Notify.
+*/
+func (ts *TermSize) Resize(cols, rows int) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.cols, ts.rows = cols, rows
+	for _, c := range ts.subs {
+		select {
+		case c <- WindowChange:
+		default:
+		}
+	}
+}
+
+/*
+Notify registers 'c' to receive WindowChange whenever the terminal is
+resized.
+*/
+func (ts *TermSize) Notify(c chan<- os.Signal) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.subs = append(ts.subs, c)
+}
+
+/*
+Stop unregisters 'c', so it no longer receives WindowChange.
+*/
+func (ts *TermSize) Stop(c chan<- os.Signal) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	for i, sub := range ts.subs {
+		if sub == c {
+			ts.subs = append(ts.subs[:i], ts.subs[i+1:]...)
+			return
+		}
+	}
+}
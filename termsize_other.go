@@ -0,0 +1,16 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package mckio
+
+/*
+WindowChange is the signal notifying a program that its terminal was
+resized.  This platform lacks SIGWINCH, so it's only delivered by TermSize.
+*/
+var WindowChange windowChange
+
+type windowChange struct{}
+
+func (windowChange) Signal() {}
+func (windowChange) String() string {
+	return "window changed"
+}
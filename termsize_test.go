@@ -0,0 +1,42 @@
+package mckio
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// layout logic under test: truncates a progress bar to the terminal width.
+func progressBar(size func() (int, int, error), done int) string {
+	width, _, _ := size()
+	bar := make([]byte, width)
+	for i := range bar {
+		bar[i] = '.'
+		if i*100 < done*width {
+			bar[i] = '#'
+		}
+	}
+	return string(bar)
+}
+func Test_TermSizeResize(t *testing.T) {
+	assrt := assert.New(t)
+	ts := NewTermSize(10, 24)
+	winch := make(chan os.Signal, 1)
+	ts.Notify(winch)
+	assrt.Equal("#####.....", progressBar(ts.Size, 50))
+	ts.Resize(4, 24)
+	assrt.Equal(WindowChange, <-winch)
+	assrt.Equal("##..", progressBar(ts.Size, 50))
+	// delivery doesn't block when the channel is full
+	ts.Resize(6, 24)
+	ts.Resize(8, 24)
+	assrt.Len(winch, 1)
+	ts.Stop(winch)
+	<-winch
+	ts.Resize(2, 24)
+	assrt.Len(winch, 0)
+	cols, rows, err := ts.Size()
+	assrt.Equal([]int{2, 24}, []int{cols, rows})
+	assrt.Nil(err)
+}
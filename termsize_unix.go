@@ -0,0 +1,11 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package mckio
+
+import "syscall"
+
+/*
+WindowChange is the signal notifying a program that its terminal was
+resized: SIGWINCH.
+*/
+var WindowChange = syscall.SIGWINCH
@@ -0,0 +1,187 @@
+package mckio
+
+import (
+	"io"
+	"sync"
+)
+
+/*
+Wansi implements an io.Writer that strips ANSI/VT100 escape sequences, like
+color codes and cursor movements, before recording the output and
+forwarding it to an optional destination.  Assertions on the recording are
+therefore immune to the styling emitted by CLI tools.
+
+- Recognizes CSI sequences (ESC [ ... final byte), string sequences
+terminated by BEL or ST, like OSC window titles and hyperlinks, and the
+remaining two or three byte escapes, like charset designations.
+
+- An escape sequence split across several writes is stripped as a whole.
+
+The following behavior of Wansi can be configured:
+
+- BehaviorKeepRawer (optional) - specifies whether the unstripped stream
+is recorded too, reported by Raw.  When undefined - the raw stream isn't
+kept.
+
+Wansi is concurrency safe.
+*/
+type Wansi struct {
+	writerEntries
+	mu      sync.Mutex
+	dest    io.Writer
+	strip   ansiStrip
+	text    []byte
+	keepRaw bool
+	raw     []byte
+}
+
+/*
+BehaviorKeepRawer specifies whether a mock transforming its output also
+keeps the original, untransformed stream.
+*/
+type BehaviorKeepRawer interface {
+	BehaviorKeepRaw() bool
+}
+
+/*
+NewWansi creates a writer stripping escape sequences before forwarding the
+remaining text to dest, whose behavior can be configured using
+BehaviorKeepRawer.  A nil dest only records the text.
+*/
+func NewWansi(dest io.Writer, behavior interface{}) *Wansi {
+	if dest == nil {
+		dest = io.Discard
+	}
+	wa := &Wansi{dest: dest}
+	if bkr, ok := behavior.(BehaviorKeepRawer); ok {
+		wa.keepRaw = bkr.BehaviorKeepRaw()
+	}
+	return wa
+}
+
+/*
+Write strips escape sequences from p, then records and forwards the text
+remaining.  It reports all of p as written unless the destination fails.
+*/
+func (wa *Wansi) Write(p []byte) (int, error) {
+	wa.entry.record(OpWrite)
+	return wa.write(p)
+}
+
+/*
+WriteString implements io.StringWriter, writing s like Write.
+*/
+func (wa *Wansi) WriteString(s string) (int, error) {
+	return wa.writeString(s, wa.write)
+}
+
+/*
+ReadFrom implements io.ReaderFrom, writing the content of 'r' until io.EOF
+as a series of writes like Write.
+*/
+func (wa *Wansi) ReadFrom(r io.Reader) (int64, error) {
+	return wa.readFrom(r, wa.write)
+}
+
+/*
+String returns the text recorded so far, free of escape sequences.
+*/
+func (wa *Wansi) String() string {
+	wa.mu.Lock()
+	defer wa.mu.Unlock()
+	return string(wa.text)
+}
+
+/*
+Raw returns the unstripped stream written so far when BehaviorKeepRawer
+enables keeping it.  Otherwise, it's empty.
+*/
+func (wa *Wansi) Raw() string {
+	wa.mu.Lock()
+	defer wa.mu.Unlock()
+	return string(wa.raw)
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+func (wa *Wansi) write(p []byte) (int, error) {
+	wa.mu.Lock()
+	defer wa.mu.Unlock()
+	if wa.keepRaw {
+		wa.raw = append(wa.raw, p...)
+	}
+	text := wa.strip.apply(p)
+	wa.text = append(wa.text, text...)
+	if len(text) == 0 {
+		return len(p), nil
+	}
+	if _, err := wa.dest.Write(text); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// ansiStrip is a state machine removing escape sequences from a stream,
+// retaining its state between writes.
+type ansiStrip struct {
+	state int
+}
+
+const (
+	ansiText = iota
+	ansiEsc
+	ansiEscInter
+	ansiCSI
+	ansiString
+	ansiStringEsc
+)
+
+const ansiEscByte = 0x1b
+
+func (as *ansiStrip) apply(p []byte) []byte {
+	text := make([]byte, 0, len(p))
+	for _, b := range p {
+		switch as.state {
+		case ansiText:
+			if b == ansiEscByte {
+				as.state = ansiEsc
+				continue
+			}
+			text = append(text, b)
+		case ansiEsc:
+			switch {
+			case b == '[':
+				as.state = ansiCSI
+			case b == ']' || b == 'P' || b == 'X' || b == '^' || b == '_':
+				// OSC, DCS, SOS, PM, and APC end with a string terminator.
+				as.state = ansiString
+			case b >= 0x20 && b <= 0x2f:
+				as.state = ansiEscInter
+			default:
+				as.state = ansiText
+			}
+		case ansiEscInter:
+			if b < 0x20 || b > 0x2f {
+				as.state = ansiText
+			}
+		case ansiCSI:
+			if b >= 0x40 && b <= 0x7e {
+				as.state = ansiText
+			}
+		case ansiString:
+			switch b {
+			case 0x07:
+				as.state = ansiText
+			case ansiEscByte:
+				as.state = ansiStringEsc
+			}
+		case ansiStringEsc:
+			// ESC \ is the string terminator.  Any other byte aborts
+			// the string as well.
+			as.state = ansiText
+		}
+	}
+	return text
+}
@@ -0,0 +1,39 @@
+package mckio
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WansiStrip(t *testing.T) {
+	assrt := assert.New(t)
+	var buf bytes.Buffer
+	wa := NewWansi(&buf, nil)
+	fmt.Fprint(wa, "\x1b[1;31merror\x1b[0m: failed\n")
+	fmt.Fprint(wa, "\x1b]0;title\x07\x1b]8;;http://x\x1b\\link\x1b]8;;\x1b\\\n")
+	fmt.Fprint(wa, "\x1b(Bplain\x1b7\x1b[2K\r100%\n")
+	assrt.Equal("error: failed\nlink\nplain\r100%\n", wa.String())
+	assrt.Equal(wa.String(), buf.String())
+	assrt.Empty(wa.Raw())
+}
+func Test_WansiSplitSequence(t *testing.T) {
+	assrt := assert.New(t)
+	wa := NewWansi(nil, keepRaw(true))
+	raw := "\x1b[38;5;196mred\x1b[0m"
+	for i := 0; i < len(raw); i++ {
+		sz, err := wa.Write([]byte{raw[i]})
+		assrt.Equal(1, sz)
+		assrt.Nil(err)
+	}
+	assrt.Equal("red", wa.String())
+	assrt.Equal(raw, wa.Raw())
+}
+
+type keepRaw bool
+
+func (kr keepRaw) BehaviorKeepRaw() bool {
+	return bool(kr)
+}
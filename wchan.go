@@ -0,0 +1,124 @@
+package mckio
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+)
+
+/*
+Wchan implements an io.Writer that sends a copy of each payload written to
+it on a channel, preserving write boundaries.  A test goroutine can
+therefore assert on output incrementally while the code under test is still
+running.
+
+The following behavior of Wchan can be configured:
+
+- BehaviorContexter (optional) - supplies a context whose cancellation
+aborts a Write blocked on the channel.  When undefined - Write blocks until
+the channel accepts the payload or Wchan is closed.
+
+- BehaviorCancelErrer (optional) - specifies the error returned by a Write
+aborted due to context cancellation.  When undefined - returns the
+context's error.
+
+Close closes the channel, signaling the end of output to its receiver.  A
+Write after, or blocked during, Close fails with os.ErrClosed.
+
+Wchan is concurrency safe.
+*/
+type Wchan struct {
+	writerEntries
+	mu        sync.Mutex
+	msgs      chan<- []byte
+	ctx       context.Context
+	cancelErr error
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+/*
+NewWchan creates an io.Writer implemented as a sending channel of byte
+slices whose behavior can be configured using BehaviorContexter and
+BehaviorCancelErrer.
+*/
+func NewWchan(msgs chan<- []byte, behavior interface{}) *Wchan {
+	wc := &Wchan{
+		msgs: msgs,
+		ctx:  context.Background(),
+		done: make(chan struct{}),
+	}
+	if bc, ok := behavior.(BehaviorContexter); ok {
+		wc.ctx = bc.BehaviorContext()
+	}
+	if bce, ok := behavior.(BehaviorCancelErrer); ok {
+		wc.cancelErr = bce.BehaviorCancelErr()
+	}
+	return wc
+}
+
+/*
+Write sends a copy of p on the channel, blocking until the channel accepts
+it.  Since p is copied, the caller may reuse it once Write returns.
+*/
+func (wc *Wchan) Write(p []byte) (int, error) {
+	wc.entry.record(OpWrite)
+	return wc.write(p)
+}
+
+/*
+WriteString implements io.StringWriter, writing s like Write.
+*/
+func (wc *Wchan) WriteString(s string) (int, error) {
+	return wc.writeString(s, wc.write)
+}
+
+/*
+ReadFrom implements io.ReaderFrom, writing the content of 'r' until io.EOF
+as a series of writes like Write.
+*/
+func (wc *Wchan) ReadFrom(r io.Reader) (int64, error) {
+	return wc.readFrom(r, wc.write)
+}
+
+/*
+Close aborts a blocked Write and closes the channel.  Calling it more than
+once is harmless.
+*/
+func (wc *Wchan) Close() error {
+	wc.closeOnce.Do(func() {
+		close(wc.done)
+		wc.mu.Lock()
+		defer wc.mu.Unlock()
+		close(wc.msgs)
+	})
+	return nil
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+func (wc *Wchan) write(p []byte) (int, error) {
+	msg := make([]byte, len(p))
+	copy(msg, p)
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	select {
+	case <-wc.done:
+		return 0, os.ErrClosed
+	default:
+	}
+	select {
+	case wc.msgs <- msg:
+		return len(p), nil
+	case <-wc.done:
+		return 0, os.ErrClosed
+	case <-wc.ctx.Done():
+		if wc.cancelErr != nil {
+			return 0, wc.cancelErr
+		}
+		return 0, wc.ctx.Err()
+	}
+}
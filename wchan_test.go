@@ -0,0 +1,57 @@
+package mckio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WchanBoundaries(t *testing.T) {
+	assrt := assert.New(t)
+	msgs := make(chan []byte)
+	wc := NewWchan(msgs, nil)
+	go func() {
+		defer wc.Close()
+		p := []byte("line 1\n")
+		wc.Write(p)
+		// reuse of p must not alter the payload already sent.
+		copy(p, "line 2\n")
+		wc.Write(p)
+		fmt.Fprint(wc, "li", "ne 3")
+	}()
+	var rslt []string
+	for msg := range msgs {
+		rslt = append(rslt, string(msg))
+	}
+	assrt.Equal([]string{"line 1\n", "line 2\n", "line 3"}, rslt)
+	_, err := wc.Write([]byte("line 4"))
+	assrt.Equal(os.ErrClosed, err)
+}
+func Test_WchanCloseBlocked(t *testing.T) {
+	assrt := assert.New(t)
+	wc := NewWchan(make(chan []byte), nil)
+	done := make(chan error)
+	go func() {
+		_, err := wc.Write([]byte("line 1"))
+		done <- err
+	}()
+	wc.Close()
+	assrt.Equal(os.ErrClosed, <-done)
+	assrt.Nil(wc.Close())
+}
+func Test_WchanContext(t *testing.T) {
+	assrt := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	wc := NewWchan(make(chan []byte), chanCtx{ctx: ctx})
+	cancel()
+	_, err := wc.Write([]byte("line 1"))
+	assrt.Equal(context.Canceled, err)
+	errCancel := errors.New("canceled")
+	wc = NewWchan(make(chan []byte), chanCtxErr{chanCtx: chanCtx{ctx: ctx}, err: errCancel})
+	_, err = wc.Write([]byte("line 1"))
+	assrt.Equal(errCancel, err)
+}
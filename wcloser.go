@@ -0,0 +1,125 @@
+package mckio
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+/*
+Wcloser implements an io.WriteCloser forwarding writes to a destination
+writer while recording every Close, so tests can prove a resource is closed
+exactly once and never written afterward.
+
+The following behavior of Wcloser can be configured:
+
+- BehaviorCloseErrer (optional) - specifies the error returned by Write
+and Close once Wcloser has been closed.  When undefined - returns
+os.ErrClosed.
+
+- Closes and WritesAfterClose report misuse instead of only failing the
+offending call, which the code under test may ignore.
+
+- Wcloser is concurrency safe.
+*/
+type Wcloser struct {
+	writerEntries
+	mu         sync.Mutex
+	dest       io.Writer
+	closeErr   error
+	closes     int
+	afterClose int
+}
+
+/*
+NewWcloser creates a writer forwarding to dest whose behavior can be
+configured using BehaviorCloseErrer.  A nil dest discards the forwarded
+writes.
+*/
+func NewWcloser(dest io.Writer, behavior interface{}) *Wcloser {
+	if dest == nil {
+		dest = io.Discard
+	}
+	wc := &Wcloser{dest: dest, closeErr: os.ErrClosed}
+	if bce, ok := behavior.(BehaviorCloseErrer); ok {
+		wc.closeErr = bce.BehaviorCloseErr()
+	}
+	return wc
+}
+
+/*
+Write forwards p to the destination writer until Wcloser is closed.
+Afterwards, it fails with the close error.
+*/
+func (wc *Wcloser) Write(p []byte) (int, error) {
+	wc.entry.record(OpWrite)
+	return wc.write(p)
+}
+
+/*
+WriteString implements io.StringWriter, writing s like Write.
+*/
+func (wc *Wcloser) WriteString(s string) (int, error) {
+	return wc.writeString(s, wc.write)
+}
+
+/*
+ReadFrom implements io.ReaderFrom, writing the content of 'r' until io.EOF
+as a series of writes like Write.
+*/
+func (wc *Wcloser) ReadFrom(r io.Reader) (int64, error) {
+	return wc.readFrom(r, wc.write)
+}
+
+/*
+Close records the close.  Like os.File, closing more than once fails with
+the close error.
+*/
+func (wc *Wcloser) Close() error {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	wc.closes++
+	if wc.closes > 1 {
+		return wc.closeErr
+	}
+	return nil
+}
+
+/*
+Closed reports whether Close has been called.
+*/
+func (wc *Wcloser) Closed() bool {
+	return wc.Closes() > 0
+}
+
+/*
+Closes reports the number of calls to Close.
+*/
+func (wc *Wcloser) Closes() int {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	return wc.closes
+}
+
+/*
+WritesAfterClose reports the number of writes attempted after Close.
+*/
+func (wc *Wcloser) WritesAfterClose() int {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	return wc.afterClose
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+func (wc *Wcloser) write(p []byte) (int, error) {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	if wc.closes > 0 {
+		wc.afterClose++
+		return 0, wc.closeErr
+	}
+	return wc.dest.Write(p)
+}
@@ -0,0 +1,37 @@
+package mckio
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WcloserWriteAfterClose(t *testing.T) {
+	assrt := assert.New(t)
+	var buf bytes.Buffer
+	wc := NewWcloser(&buf, nil)
+	assrt.False(wc.Closed())
+	_, err := wc.Write([]byte("log 1\n"))
+	assrt.Nil(err)
+	assrt.Nil(wc.Close())
+	assrt.True(wc.Closed())
+	sz, err := wc.Write([]byte("log 2\n"))
+	assrt.Zero(sz)
+	assrt.Equal(os.ErrClosed, err)
+	assrt.Equal(1, wc.WritesAfterClose())
+	assrt.Equal(1, wc.Closes())
+	assrt.Equal("log 1\n", buf.String())
+}
+func Test_WcloserCloseTwice(t *testing.T) {
+	assrt := assert.New(t)
+	errClose := errors.New("closed")
+	wc := NewWcloser(nil, closeErr{err: errClose})
+	assrt.Nil(wc.Close())
+	assrt.Equal(errClose, wc.Close())
+	assrt.Equal(2, wc.Closes())
+	_, err := wc.Write([]byte("log 1\n"))
+	assrt.Equal(errClose, err)
+}
@@ -0,0 +1,105 @@
+package mckio
+
+import (
+	"io"
+	"sync"
+)
+
+/*
+Operation names of the entry points offered by writer mocks, in addition
+to OpWrite.
+*/
+const (
+	OpWriteString = "WriteString"
+	OpReadFrom    = "ReadFrom"
+)
+
+/*
+EntryPoints reports the number of calls to each entry point of a writer
+mock, revealing whether the code under test used Write or the fast paths
+taken by fmt.Fprint and io.Copy: io.StringWriter and io.ReaderFrom.  Calls
+to ReadFrom don't also count as calls to Write.
+*/
+type EntryPoints struct {
+	Write       int
+	WriteString int
+	ReadFrom    int
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+// writerEntries counts the calls to each entry point shared by writer
+// mocks.  A mock embeds it, records OpWrite in its own Write, and funnels
+// its WriteString and ReadFrom through writeString and readFrom, passing
+// the write function shared with Write.  Since the mock supplies that
+// function on every call, its zero value, or one built as a literal,
+// offers every entry point.
+type writerEntries struct {
+	entry entryCount
+}
+
+func (we *writerEntries) writeString(s string, write func(p []byte) (int, error)) (int, error) {
+	we.entry.record(OpWriteString)
+	return write([]byte(s))
+}
+func (we *writerEntries) readFrom(r io.Reader, write func(p []byte) (int, error)) (int64, error) {
+	we.entry.record(OpReadFrom)
+	return readFrom(r, write)
+}
+
+/*
+EntryPoints reports the number of calls to Write, WriteString, and ReadFrom.
+*/
+func (we *writerEntries) EntryPoints() EntryPoints {
+	return we.entry.get()
+}
+
+type entryCount struct {
+	mu     sync.Mutex
+	counts EntryPoints
+}
+
+func (ec *entryCount) record(op string) {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	switch op {
+	case OpWrite:
+		ec.counts.Write++
+	case OpWriteString:
+		ec.counts.WriteString++
+	case OpReadFrom:
+		ec.counts.ReadFrom++
+	}
+}
+func (ec *entryCount) get() EntryPoints {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	return ec.counts
+}
+
+// readFrom copies r to write, one buffer of bytes per call, mirroring
+// io.Copy.  A write accepting fewer bytes than provided ends the copy.
+func readFrom(r io.Reader, write func(p []byte) (int, error)) (n int64, err error) {
+	buf := make([]byte, 32*1024)
+	for {
+		nr, rerr := r.Read(buf)
+		if nr > 0 {
+			nw, werr := write(buf[:nr])
+			n += int64(nw)
+			if werr != nil {
+				return n, werr
+			}
+			if nw < nr {
+				return n, io.ErrShortWrite
+			}
+		}
+		if rerr == io.EOF {
+			return n, nil
+		}
+		if rerr != nil {
+			return n, rerr
+		}
+	}
+}
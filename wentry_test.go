@@ -0,0 +1,82 @@
+package mckio
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WentryPoints(t *testing.T) {
+	assrt := assert.New(t)
+	ws := NewWstrings(nil)
+	ws.Write([]byte("line 1\n"))
+	io.WriteString(ws, "line 2\n")
+	// hide strings.Reader's io.WriterTo so io.Copy uses io.ReaderFrom
+	sz, err := io.Copy(ws, onlyReader{strings.NewReader("line 3\n")})
+	assrt.Equal(int64(7), sz)
+	assrt.Nil(err)
+	assrt.Equal([]string{"line 1", "line 2", "line 3"}, ws.Lines())
+	assrt.Equal(EntryPoints{Write: 1, WriteString: 1, ReadFrom: 1}, ws.EntryPoints())
+}
+func Test_WentryTee(t *testing.T) {
+	assrt := assert.New(t)
+	var dest bytes.Buffer
+	wt := NewWtee(&dest)
+	io.WriteString(wt, "line 1\n")
+	io.Copy(wt, onlyReader{strings.NewReader("line 2\n")})
+	wt.Write([]byte("line 3\n"))
+	assrt.Equal("line 1\nline 2\nline 3\n", dest.String())
+	assrt.Equal(dest.String(), wt.String())
+	assrt.Equal(EntryPoints{Write: 1, WriteString: 1, ReadFrom: 1}, wt.EntryPoints())
+	assrt.Equal(wt.EntryPoints(), wt.Wrecorder.EntryPoints())
+}
+func Test_WentryReadFromShort(t *testing.T) {
+	assrt := assert.New(t)
+	var dest bytes.Buffer
+	ws := NewWshort(&dest, chunkSize(4))
+	sz, err := ws.ReadFrom(strings.NewReader("0123456789"))
+	assrt.Equal(int64(4), sz)
+	assrt.Equal(io.ErrShortWrite, err)
+	assrt.Equal("0123", dest.String())
+}
+func Test_WentryReadFromErr(t *testing.T) {
+	assrt := assert.New(t)
+	errRead := io.ErrUnexpectedEOF
+	ws := NewWstrings(nil)
+	sz, err := ws.ReadFrom(io.MultiReader(strings.NewReader("line 1\n"), errReader{err: errRead}))
+	assrt.Equal(int64(7), sz)
+	assrt.Equal(errRead, err)
+}
+
+type onlyReader struct {
+	io.Reader
+}
+
+func Test_WentryFreezeText(t *testing.T) {
+	assrt := assert.New(t)
+	var dest bytes.Buffer
+	wf := NewWfreeze(3, &dest)
+	wf.Write([]byte("a"))
+	io.WriteString(wf, "b")
+	io.Copy(wf, onlyReader{strings.NewReader("c")})
+	assrt.Equal("abc", dest.String())
+	assrt.Equal(3, wf.Writes())
+	assrt.Equal(EntryPoints{Write: 1, WriteString: 1, ReadFrom: 1}, wf.EntryPoints())
+	wt := NewWtext()
+	wt.Write([]byte("a"))
+	io.WriteString(wt, "\xc3")
+	io.Copy(wt, onlyReader{strings.NewReader("\xa9")})
+	assrt.Equal("aé", wt.Preview(-1))
+	assrt.Equal(EntryPoints{Write: 1, WriteString: 1, ReadFrom: 1}, wt.EntryPoints())
+}
+func Test_WentryZeroValue(t *testing.T) {
+	assrt := assert.New(t)
+	var wt Wtext
+	io.WriteString(&wt, "line 1\n")
+	io.Copy(&wt, onlyReader{strings.NewReader("line 2\n")})
+	assrt.Equal("line 1\nline 2\n", wt.String())
+	assrt.Equal(EntryPoints{WriteString: 1, ReadFrom: 1}, wt.EntryPoints())
+}
@@ -0,0 +1,210 @@
+package mckio
+
+import (
+	"io"
+	"os"
+	"sync"
+	"syscall"
+)
+
+/*
+Werr implements an io.Writer that forwards writes to a destination writer
+until configured to fail, so code paths handling write failures, like a
+full disk or closed pipe, can be exercised deterministically.
+
+The following behavior of Werr can be configured:
+
+- BehaviorErrInjector (optional) - specifies the error, if any, returned
+by a specific call of OpWrite instead of forwarding it.  FailAfterCalls and
+BrokenPipe implement it.  When undefined - calls never fail.
+
+- BehaviorWriteQuotaer (optional) - specifies the number of bytes forwarded
+before writes fail.  The write exceeding the quota forwards the bytes
+remaining within it and returns the count along with the error.
+FailAfterBytes and DiskFull implement it.  When undefined - bytes are unlimited.
+
+- Werr is concurrency safe.
+*/
+type Werr struct {
+	writerEntries
+	mu       sync.Mutex
+	dest     io.Writer
+	inject   func(op string, call int) error
+	quota    int64
+	quotaErr error
+	writes   int
+	written  int64
+}
+
+/*
+BehaviorWriteQuotaer specifies the number of bytes a writer accepts before
+failing every subsequent write with 'err'.
+*/
+type BehaviorWriteQuotaer interface {
+	BehaviorWriteQuota() (limit int64, err error)
+}
+
+/*
+FailAfterCalls implements BehaviorErrInjector by failing every write after
+the first Calls writes with Err.  Embed it in a behavior struct to combine
+it with other behaviors.
+*/
+type FailAfterCalls struct {
+	Calls int
+	Err   error
+}
+
+/*
+BehaviorErrInject returns Err for write calls beyond Calls.
+*/
+func (fc FailAfterCalls) BehaviorErrInject(op string, call int) error {
+	if op == OpWrite && call > fc.Calls {
+		return fc.Err
+	}
+	return nil
+}
+
+/*
+FailAfterBytes implements BehaviorWriteQuotaer by failing writes with Err
+once Bytes have been accepted.
+*/
+type FailAfterBytes struct {
+	Bytes int64
+	Err   error
+}
+
+/*
+BehaviorWriteQuota returns Bytes as the limit and Err as its error.
+*/
+func (fb FailAfterBytes) BehaviorWriteQuota() (limit int64, err error) {
+	return fb.Bytes, fb.Err
+}
+
+/*
+BrokenPipe implements BehaviorErrInjector simulating a write to a dead
+downstream process.  Writes after the first Calls fail with syscall.EPIPE
+wrapped as the OS reports it: in an *os.PathError naming Path or, when
+Path is empty, in an *os.SyscallError.
+*/
+type BrokenPipe struct {
+	Calls int
+	Path  string
+}
+
+/*
+BehaviorErrInject returns a "broken pipe" error for write calls beyond
+Calls.
+*/
+func (bp BrokenPipe) BehaviorErrInject(op string, call int) error {
+	if op != OpWrite || call <= bp.Calls {
+		return nil
+	}
+	if bp.Path == "" {
+		return os.NewSyscallError("write", syscall.EPIPE)
+	}
+	return &os.PathError{Op: "write", Path: bp.Path, Err: syscall.EPIPE}
+}
+
+/*
+DiskFull implements BehaviorWriteQuotaer simulating a filling disk.  Once
+Bytes have been accepted, writes persistently fail with syscall.ENOSPC
+wrapped in an *os.PathError naming Path, as os.File reports it.
+*/
+type DiskFull struct {
+	Bytes int64
+	Path  string
+}
+
+/*
+BehaviorWriteQuota returns Bytes as the limit and a "no space left on
+device" error as its error.
+*/
+func (df DiskFull) BehaviorWriteQuota() (limit int64, err error) {
+	return df.Bytes, &os.PathError{Op: "write", Path: df.Path, Err: syscall.ENOSPC}
+}
+
+/*
+NewWerr creates a writer forwarding to dest whose failures are configured
+using BehaviorErrInjector and BehaviorWriteQuotaer.  A nil dest discards
+the forwarded writes.
+*/
+func NewWerr(dest io.Writer, behavior interface{}) *Werr {
+	if dest == nil {
+		dest = io.Discard
+	}
+	we := &Werr{dest: dest, quota: -1}
+	we.inject = func(string, int) error { return nil }
+	if ei, ok := behavior.(BehaviorErrInjector); ok {
+		we.inject = ei.BehaviorErrInject
+	}
+	if wq, ok := behavior.(BehaviorWriteQuotaer); ok {
+		we.quota, we.quotaErr = wq.BehaviorWriteQuota()
+	}
+	return we
+}
+
+/*
+Write forwards p to the destination writer unless the current call or the
+byte quota is configured to fail.
+*/
+func (we *Werr) Write(p []byte) (int, error) {
+	we.entry.record(OpWrite)
+	return we.write(p)
+}
+
+/*
+WriteString implements io.StringWriter, writing s like Write.
+*/
+func (we *Werr) WriteString(s string) (int, error) {
+	return we.writeString(s, we.write)
+}
+
+/*
+ReadFrom implements io.ReaderFrom, writing the content of 'r' until io.EOF
+as a series of writes like Write.
+*/
+func (we *Werr) ReadFrom(r io.Reader) (int64, error) {
+	return we.readFrom(r, we.write)
+}
+
+/*
+Writes reports the number of calls to Write, including those that failed.
+*/
+func (we *Werr) Writes() int {
+	we.mu.Lock()
+	defer we.mu.Unlock()
+	return we.writes
+}
+
+/*
+Written reports the number of bytes forwarded to the destination writer.
+*/
+func (we *Werr) Written() int64 {
+	we.mu.Lock()
+	defer we.mu.Unlock()
+	return we.written
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+func (we *Werr) write(p []byte) (int, error) {
+	we.mu.Lock()
+	defer we.mu.Unlock()
+	we.writes++
+	if err := we.inject(OpWrite, we.writes); err != nil {
+		return 0, err
+	}
+	var err error
+	if we.quota > -1 && we.written+int64(len(p)) > we.quota {
+		p = p[:we.quota-we.written]
+		err = we.quotaErr
+	}
+	n, derr := we.dest.Write(p)
+	we.written += int64(n)
+	if derr != nil {
+		return n, derr
+	}
+	return n, err
+}
@@ -0,0 +1,82 @@
+package mckio
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WerrFailAfterCalls(t *testing.T) {
+	assrt := assert.New(t)
+	var buf bytes.Buffer
+	we := NewWerr(&buf, FailAfterCalls{Calls: 2, Err: syscall.EPIPE})
+	for _, msg := range []string{"log 1\n", "log 2\n"} {
+		sz, err := we.Write([]byte(msg))
+		assrt.Equal(len(msg), sz)
+		assrt.Nil(err)
+	}
+	sz, err := we.Write([]byte("log 3\n"))
+	assrt.Zero(sz)
+	assrt.Equal(syscall.EPIPE, err)
+	assrt.Equal(3, we.Writes())
+	assrt.Equal(int64(12), we.Written())
+	assrt.Equal("log 1\nlog 2\n", buf.String())
+}
+func Test_WerrFailAfterBytes(t *testing.T) {
+	assrt := assert.New(t)
+	var buf bytes.Buffer
+	we := NewWerr(&buf, FailAfterBytes{Bytes: 8, Err: syscall.ENOSPC})
+	sz, err := we.Write([]byte("log 1\n"))
+	assrt.Equal(6, sz)
+	assrt.Nil(err)
+	sz, err = we.Write([]byte("log 2\n"))
+	assrt.Equal(2, sz)
+	assrt.Equal(syscall.ENOSPC, err)
+	sz, err = we.Write([]byte("log 3\n"))
+	assrt.Zero(sz)
+	assrt.Equal(syscall.ENOSPC, err)
+	assrt.Equal("log 1\nlo", buf.String())
+}
+func Test_WerrDestErr(t *testing.T) {
+	assrt := assert.New(t)
+	errDest := errors.New("dest failed")
+	we := NewWerr(NewWerr(nil, FailAfterCalls{Err: errDest}), nil)
+	_, err := we.Write([]byte("log 1\n"))
+	assrt.Equal(errDest, err)
+	assrt.Zero(we.Written())
+}
+func Test_WerrDiskFull(t *testing.T) {
+	assrt := assert.New(t)
+	var buf bytes.Buffer
+	we := NewWerr(&buf, DiskFull{Bytes: 8, Path: "app.log"})
+	_, err := we.Write([]byte("log 1\n"))
+	assrt.Nil(err)
+	for i := 0; i < 2; i++ {
+		_, err = we.Write([]byte("log 2\n"))
+		assrt.True(errors.Is(err, syscall.ENOSPC))
+		var perr *os.PathError
+		assrt.True(errors.As(err, &perr))
+		assrt.Equal("write app.log: no space left on device", err.Error())
+	}
+	assrt.Equal("log 1\nlo", buf.String())
+}
+func Test_WerrBrokenPipe(t *testing.T) {
+	assrt := assert.New(t)
+	we := NewWerr(nil, BrokenPipe{Calls: 1, Path: "|downstream"})
+	_, err := we.Write([]byte("log 1\n"))
+	assrt.Nil(err)
+	_, err = we.Write([]byte("log 2\n"))
+	assrt.True(errors.Is(err, syscall.EPIPE))
+	var perr *os.PathError
+	assrt.True(errors.As(err, &perr))
+	we = NewWerr(nil, BrokenPipe{})
+	_, err = we.Write([]byte("log 1\n"))
+	assrt.True(errors.Is(err, syscall.EPIPE))
+	var serr *os.SyscallError
+	assrt.True(errors.As(err, &serr))
+	assrt.Equal("write: broken pipe", err.Error())
+}
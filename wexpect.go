@@ -0,0 +1,177 @@
+package mckio
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+/*
+Wexpect implements an io.Writer asserting that the code under test writes
+an ordered script of payloads.  Each Write must satisfy the next
+WriteMatcher of the script.
+
+- The first out-of-order or unexpected write fails the testing.TB supplied
+to NewWexpect, when not nil, and is returned as an error by that Write and
+every subsequent one.  Err also reports it.
+
+- Remaining reports the number of expected writes not yet performed, so a
+test can verify the whole script was written.
+
+- Wexpect is concurrency safe, although a script is only meaningful for
+writes whose order is deterministic.
+*/
+type Wexpect struct {
+	writerEntries
+	mu     sync.Mutex
+	tb     testing.TB
+	script []WriteMatcher
+	writes int
+	err    error
+}
+
+/*
+WriteMatcher decides whether a write's payload satisfies an expectation.
+String describes the expectation in failure messages.
+*/
+type WriteMatcher interface {
+	MatchWrite(p []byte) bool
+	String() string
+}
+
+/*
+ExpectExact matches a payload equal to s.
+*/
+func ExpectExact(s string) WriteMatcher {
+	return matchFunc{
+		desc:  fmt.Sprintf("exactly %q", s),
+		match: func(p []byte) bool { return string(p) == s },
+	}
+}
+
+/*
+ExpectPrefix matches a payload beginning with s.
+*/
+func ExpectPrefix(s string) WriteMatcher {
+	return matchFunc{
+		desc:  fmt.Sprintf("prefix %q", s),
+		match: func(p []byte) bool { return strings.HasPrefix(string(p), s) },
+	}
+}
+
+/*
+ExpectContains matches a payload containing s.
+*/
+func ExpectContains(s string) WriteMatcher {
+	return matchFunc{
+		desc:  fmt.Sprintf("containing %q", s),
+		match: func(p []byte) bool { return strings.Contains(string(p), s) },
+	}
+}
+
+/*
+ExpectRegexp matches a payload matching the regular expression expr.  It
+panics if expr fails to compile, as a malformed expression is a defect of
+the test itself.
+*/
+func ExpectRegexp(expr string) WriteMatcher {
+	re := regexp.MustCompile(expr)
+	return matchFunc{
+		desc:  fmt.Sprintf("regexp %q", expr),
+		match: re.Match,
+	}
+}
+
+/*
+NewWexpect creates a writer expecting the writes described by script.  A
+nil tb only reports failures as errors.
+*/
+func NewWexpect(tb testing.TB, script ...WriteMatcher) *Wexpect {
+	return &Wexpect{tb: tb, script: script}
+}
+
+/*
+Write verifies p satisfies the next expectation of the script.
+*/
+func (we *Wexpect) Write(p []byte) (int, error) {
+	we.entry.record(OpWrite)
+	return we.write(p)
+}
+
+/*
+WriteString implements io.StringWriter, writing s like Write.
+*/
+func (we *Wexpect) WriteString(s string) (int, error) {
+	return we.writeString(s, we.write)
+}
+
+/*
+ReadFrom implements io.ReaderFrom, writing the content of 'r' until io.EOF
+as a series of writes like Write.
+*/
+func (we *Wexpect) ReadFrom(r io.Reader) (int64, error) {
+	return we.readFrom(r, we.write)
+}
+
+/*
+Err returns the failure detected or nil.
+*/
+func (we *Wexpect) Err() error {
+	we.mu.Lock()
+	defer we.mu.Unlock()
+	return we.err
+}
+
+/*
+Remaining returns the number of expected writes not yet satisfied.
+*/
+func (we *Wexpect) Remaining() int {
+	we.mu.Lock()
+	defer we.mu.Unlock()
+	if we.err != nil {
+		// the failing write didn't satisfy its expectation.
+		return len(we.script) - we.writes + 1
+	}
+	return len(we.script) - we.writes
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+func (we *Wexpect) write(p []byte) (int, error) {
+	we.mu.Lock()
+	defer we.mu.Unlock()
+	if we.err != nil {
+		return 0, we.err
+	}
+	we.writes++
+	switch {
+	case we.writes > len(we.script):
+		we.err = fmt.Errorf("mckio: unexpected write %d \"%s\" after script completed", we.writes, excerpt(string(p)))
+	case !we.script[we.writes-1].MatchWrite(p):
+		we.err = fmt.Errorf("mckio: write %d \"%s\" doesn't match expected %s", we.writes, excerpt(string(p)), we.script[we.writes-1])
+	default:
+		return len(p), nil
+	}
+	if we.tb != nil {
+		we.tb.Helper()
+		we.tb.Errorf("%v", we.err)
+	}
+	return 0, we.err
+}
+
+type matchFunc struct {
+	desc  string
+	match func(p []byte) bool
+}
+
+func (mf matchFunc) MatchWrite(p []byte) bool {
+	return mf.match(p)
+}
+func (mf matchFunc) String() string {
+	return mf.desc
+}
@@ -0,0 +1,52 @@
+package mckio
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WexpectScript(t *testing.T) {
+	assrt := assert.New(t)
+	we := NewWexpect(t, ExpectExact("login: "), ExpectPrefix("welcome"), ExpectRegexp(`^\$ $`))
+	assrt.Equal(3, we.Remaining())
+	fmt.Fprint(we, "login: ")
+	fmt.Fprint(we, "welcome admin\n")
+	sz, err := fmt.Fprint(we, "$ ")
+	assrt.Equal(2, sz)
+	assrt.Nil(err)
+	assrt.Zero(we.Remaining())
+	assrt.Nil(we.Err())
+}
+func Test_WexpectOutOfOrder(t *testing.T) {
+	assrt := assert.New(t)
+	tb := &tbRecord{TB: t}
+	we := NewWexpect(tb, ExpectExact("login: "), ExpectPrefix("welcome"))
+	sz, err := fmt.Fprint(we, "welcome admin")
+	assrt.Zero(sz)
+	assrt.EqualError(err, `mckio: write 1 "welcome admin" doesn't match expected exactly "login: "`)
+	assrt.Equal([]string{err.Error()}, tb.errs)
+	// failure is sticky and reported once
+	_, err = fmt.Fprint(we, "login: ")
+	assrt.Equal(we.Err(), err)
+	assrt.Len(tb.errs, 1)
+	assrt.Equal(2, we.Remaining())
+}
+func Test_WexpectUnexpected(t *testing.T) {
+	assrt := assert.New(t)
+	we := NewWexpect(nil, ExpectExact("login: "))
+	fmt.Fprint(we, "login: ")
+	_, err := fmt.Fprint(we, "login: ")
+	assrt.EqualError(err, `mckio: unexpected write 2 "login: " after script completed`)
+	assrt.Zero(we.Remaining())
+}
+func Test_WexpectContains(t *testing.T) {
+	assrt := assert.New(t)
+	we := NewWexpect(nil, ExpectContains("error"))
+	_, err := fmt.Fprint(we, "fatal: no such file")
+	assrt.EqualError(err, `mckio: write 1 "fatal: no such file" doesn't match expected containing "error"`)
+	we = NewWexpect(nil, ExpectContains("error"))
+	_, err = fmt.Fprint(we, "an error occurred")
+	assrt.Nil(err)
+}
@@ -0,0 +1,137 @@
+package mckio
+
+import (
+	"io"
+	"sync"
+)
+
+/*
+Wflush implements an io.Writer with a Flush method, in the style of
+bufio.Writer and http.Flusher.  Writes remain pending until flushed, when
+they're forwarded to a destination writer, so code that must flush at
+specific points, like before a prompt or exit, can be verified.
+
+The following behavior of Wflush can be configured:
+
+- BehaviorErrInjector (optional) - specifies the error, if any, returned
+by a specific call of OpFlush, which then retains the pending bytes.  It's
+also consulted for OpWrite.  When undefined - calls never fail.
+
+- Flushes reports the bytes delivered by each successful Flush, including
+empty ones, while Pending reports the bytes awaiting a Flush.
+
+- Wflush is concurrency safe.
+*/
+type Wflush struct {
+	writerEntries
+	mu      sync.Mutex
+	dest    io.Writer
+	inject  func(op string, call int) error
+	pend    []byte
+	writes  int
+	calls   int
+	flushes []string
+}
+
+/*
+OpFlush names the Flush operation supplied to behaviors.
+*/
+const OpFlush = "Flush"
+
+/*
+NewWflush creates a writer forwarding flushed bytes to dest whose failures
+are configured using BehaviorErrInjector.  A nil dest discards the flushed
+bytes.
+*/
+func NewWflush(dest io.Writer, behavior interface{}) *Wflush {
+	if dest == nil {
+		dest = io.Discard
+	}
+	wf := &Wflush{dest: dest}
+	wf.inject = func(string, int) error { return nil }
+	if ei, ok := behavior.(BehaviorErrInjector); ok {
+		wf.inject = ei.BehaviorErrInject
+	}
+	return wf
+}
+
+/*
+Write appends p to the bytes pending a Flush.
+*/
+func (wf *Wflush) Write(p []byte) (int, error) {
+	wf.entry.record(OpWrite)
+	return wf.write(p)
+}
+
+/*
+WriteString implements io.StringWriter, writing s like Write.
+*/
+func (wf *Wflush) WriteString(s string) (int, error) {
+	return wf.writeString(s, wf.write)
+}
+
+/*
+ReadFrom implements io.ReaderFrom, writing the content of 'r' until io.EOF
+as a series of writes like Write.
+*/
+func (wf *Wflush) ReadFrom(r io.Reader) (int64, error) {
+	return wf.readFrom(r, wf.write)
+}
+
+/*
+Flush forwards the pending bytes to the destination writer.  On failure,
+bytes the destination didn't accept remain pending.
+*/
+func (wf *Wflush) Flush() error {
+	wf.mu.Lock()
+	defer wf.mu.Unlock()
+	wf.calls++
+	if err := wf.inject(OpFlush, wf.calls); err != nil {
+		return err
+	}
+	n, err := wf.dest.Write(wf.pend)
+	if err == nil && n < len(wf.pend) {
+		err = io.ErrShortWrite
+	}
+	if err != nil {
+		wf.pend = append(wf.pend[:0], wf.pend[n:]...)
+		return err
+	}
+	wf.flushes = append(wf.flushes, string(wf.pend))
+	wf.pend = wf.pend[:0]
+	return nil
+}
+
+/*
+Flushes returns the bytes delivered by each successful Flush in the order
+they occurred.
+*/
+func (wf *Wflush) Flushes() []string {
+	wf.mu.Lock()
+	defer wf.mu.Unlock()
+	return append([]string(nil), wf.flushes...)
+}
+
+/*
+Pending returns the bytes written but not yet flushed.
+*/
+func (wf *Wflush) Pending() string {
+	wf.mu.Lock()
+	defer wf.mu.Unlock()
+	return string(wf.pend)
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+func (wf *Wflush) write(p []byte) (int, error) {
+	wf.mu.Lock()
+	defer wf.mu.Unlock()
+	wf.writes++
+	if err := wf.inject(OpWrite, wf.writes); err != nil {
+		return 0, err
+	}
+	wf.pend = append(wf.pend, p...)
+	return len(p), nil
+}
@@ -0,0 +1,61 @@
+package mckio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WflushPoints(t *testing.T) {
+	assrt := assert.New(t)
+	var dest bytes.Buffer
+	wf := NewWflush(&dest, nil)
+	fmt.Fprint(wf, "banner\n")
+	fmt.Fprint(wf, "login: ")
+	assrt.Empty(dest.String())
+	assrt.Equal("banner\nlogin: ", wf.Pending())
+	assrt.Nil(wf.Flush())
+	assrt.Nil(wf.Flush())
+	fmt.Fprint(wf, "bye\n")
+	assrt.Equal([]string{"banner\nlogin: ", ""}, wf.Flushes())
+	assrt.Equal("bye\n", wf.Pending())
+	assrt.Equal("banner\nlogin: ", dest.String())
+}
+func Test_WflushErr(t *testing.T) {
+	assrt := assert.New(t)
+	errFlush := errors.New("flush failed")
+	var dest bytes.Buffer
+	wf := NewWflush(&dest, flushErr{call: 1, err: errFlush})
+	fmt.Fprint(wf, "login: ")
+	assrt.Equal(errFlush, wf.Flush())
+	assrt.Equal("login: ", wf.Pending())
+	assrt.Empty(wf.Flushes())
+	assrt.Nil(wf.Flush())
+	assrt.Equal("login: ", dest.String())
+}
+func Test_WflushDestShort(t *testing.T) {
+	assrt := assert.New(t)
+	var dest bytes.Buffer
+	wf := NewWflush(NewWshort(&dest, chunkSize(4)), nil)
+	fmt.Fprint(wf, "login: ")
+	assrt.Equal(io.ErrShortWrite, wf.Flush())
+	assrt.Equal("n: ", wf.Pending())
+	assrt.Nil(wf.Flush())
+	assrt.Equal("login: ", dest.String())
+}
+
+type flushErr struct {
+	call int
+	err  error
+}
+
+func (fe flushErr) BehaviorErrInject(op string, call int) error {
+	if op == OpFlush && call == fe.call {
+		return fe.err
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package mckio
+
+import (
+	"io"
+	"sync"
+)
+
+/*
+Wfreeze implements an io.Writer that accepts a fixed number of writes and
+then blocks every subsequent write until released.  It reproduces the
+"logging pipeline stalled" class of deadlocks under test control.
+
+- Accepted writes are forwarded to the io.Writer supplied to NewWfreeze.
+
+- Frozen returns a channel closed when the first write blocks, so a test
+can wait for the stall to occur instead of sleeping.
+
+- Release unblocks all blocked writes and permits every future write.
+
+- Wfreeze is concurrency safe.
+*/
+type Wfreeze struct {
+	writerEntries
+	mu      sync.Mutex
+	accept  int
+	writes  int
+	dest    io.Writer
+	frozen  chan struct{}
+	freeze  sync.Once
+	release chan struct{}
+	relOnce sync.Once
+}
+
+/*
+NewWfreeze creates a writer that forwards the first 'accept' writes to dest
+and then freezes.  A nil dest discards the accepted writes.
+*/
+func NewWfreeze(accept int, dest io.Writer) *Wfreeze {
+	if dest == nil {
+		dest = io.Discard
+	}
+	wf := &Wfreeze{
+		accept:  accept,
+		dest:    dest,
+		frozen:  make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	return wf
+}
+
+/*
+Write forwards p to the destination writer until the accepted number of
+writes has been exhausted.  Afterwards, it blocks until Release is called.
+*/
+func (wf *Wfreeze) Write(p []byte) (int, error) {
+	wf.entry.record(OpWrite)
+	return wf.write(p)
+}
+
+/*
+WriteString implements io.StringWriter, writing s like Write.
+*/
+func (wf *Wfreeze) WriteString(s string) (int, error) {
+	return wf.writeString(s, wf.write)
+}
+
+/*
+ReadFrom implements io.ReaderFrom, writing the content of 'r' until io.EOF
+as a series of writes like Write.
+*/
+func (wf *Wfreeze) ReadFrom(r io.Reader) (int64, error) {
+	return wf.readFrom(r, wf.write)
+}
+
+/*
+Frozen returns a channel that's closed once a write blocks.
+*/
+func (wf *Wfreeze) Frozen() <-chan struct{} {
+	return wf.frozen
+}
+
+/*
+Release unblocks all frozen writes and lets future writes proceed.  Calling
+it more than once is harmless.
+*/
+func (wf *Wfreeze) Release() {
+	wf.relOnce.Do(func() { close(wf.release) })
+}
+
+/*
+Writes reports the number of writes forwarded to the destination writer.
+*/
+func (wf *Wfreeze) Writes() int {
+	wf.mu.Lock()
+	defer wf.mu.Unlock()
+	return wf.writes
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+func (wf *Wfreeze) write(p []byte) (int, error) {
+	wf.mu.Lock()
+	if wf.writes < wf.accept {
+		defer wf.mu.Unlock()
+		wf.writes++
+		return wf.dest.Write(p)
+	}
+	wf.freeze.Do(func() { close(wf.frozen) })
+	wf.mu.Unlock()
+	<-wf.release
+	wf.mu.Lock()
+	defer wf.mu.Unlock()
+	wf.writes++
+	return wf.dest.Write(p)
+}
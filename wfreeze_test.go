@@ -0,0 +1,47 @@
+package mckio
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WfreezeAcceptThenBlock(t *testing.T) {
+	assrt := assert.New(t)
+	var buf bytes.Buffer
+	wf := NewWfreeze(2, &buf)
+	for _, msg := range []string{"log 1\n", "log 2\n"} {
+		sz, err := wf.Write([]byte(msg))
+		assrt.Equal(len(msg), sz)
+		assrt.Nil(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wf.Write([]byte("log 3\n"))
+	}()
+	<-wf.Frozen()
+	select {
+	case <-done:
+		assrt.Fail("write should remain blocked until released")
+	case <-time.After(10 * time.Millisecond):
+	}
+	assrt.Equal(2, wf.Writes())
+	wf.Release()
+	<-done
+	assrt.Equal(3, wf.Writes())
+	assrt.Equal("log 1\nlog 2\nlog 3\n", buf.String())
+	// release is idempotent and writes no longer block
+	wf.Release()
+	_, err := wf.Write([]byte("log 4\n"))
+	assrt.Nil(err)
+}
+func Test_WfreezeNilDestination(t *testing.T) {
+	assrt := assert.New(t)
+	wf := NewWfreeze(1, nil)
+	sz, err := wf.Write([]byte("discarded"))
+	assrt.Equal(len("discarded"), sz)
+	assrt.Nil(err)
+}
@@ -0,0 +1,185 @@
+package mckio
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"sync"
+)
+
+/*
+Wlines implements an io.Writer that accumulates partial writes and sends
+one channel message per complete line, excluding its delimiter.  A test can
+therefore assert the lines a program printed, in order, regardless of how
+the program chunked its writes.
+
+The following behavior of Wlines can be configured:
+
+- BehaviorDelimer (optional) - specifies the byte sequence terminating a
+line.  When undefined - lines are terminated by a newline.
+
+- BehaviorContexter (optional) - supplies a context whose cancellation
+aborts a Write blocked on the channel.  When undefined - Write blocks until
+the channel accepts the line or Wlines is closed.
+
+- BehaviorCancelErrer (optional) - specifies the error returned by a Write
+aborted due to context cancellation.  When undefined - returns the
+context's error.
+
+A Write aborted while sending a line reports the bytes of 'p' belonging to
+the lines already sent and discards the rest of 'p', so the writer may
+retry.
+
+Close hands the final unterminated line, if any, to a goroutine that sends
+it then closes the channel, so Close never waits for a receiver.  The
+goroutine ends once the line is received or the context is canceled.  A
+Write after, or blocked during, Close fails with os.ErrClosed.
+
+Wlines is concurrency safe.
+*/
+type Wlines struct {
+	writerEntries
+	mu        sync.Mutex
+	lines     chan<- string
+	delim     []byte
+	pend      []byte
+	ctx       context.Context
+	cancelErr error
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+/*
+NewWlines creates an io.Writer sending complete lines on a channel of
+strings whose behavior can be configured using BehaviorDelimer,
+BehaviorContexter, and BehaviorCancelErrer.
+*/
+func NewWlines(lines chan<- string, behavior interface{}) *Wlines {
+	wl := &Wlines{
+		lines: lines,
+		delim: []byte{'\n'},
+		ctx:   context.Background(),
+		done:  make(chan struct{}),
+	}
+	if bd, ok := behavior.(BehaviorDelimer); ok && len(bd.BehaviorDelim()) > 0 {
+		wl.delim = bd.BehaviorDelim()
+	}
+	if bc, ok := behavior.(BehaviorContexter); ok {
+		wl.ctx = bc.BehaviorContext()
+	}
+	if bce, ok := behavior.(BehaviorCancelErrer); ok {
+		wl.cancelErr = bce.BehaviorCancelErr()
+	}
+	return wl
+}
+
+/*
+Write appends p to the pending partial line and sends every line it
+completes.
+*/
+func (wl *Wlines) Write(p []byte) (int, error) {
+	wl.entry.record(OpWrite)
+	return wl.write(p)
+}
+
+/*
+WriteString implements io.StringWriter, writing s like Write.
+*/
+func (wl *Wlines) WriteString(s string) (int, error) {
+	return wl.writeString(s, wl.write)
+}
+
+/*
+ReadFrom implements io.ReaderFrom, writing the content of 'r' until io.EOF
+as a series of writes like Write.
+*/
+func (wl *Wlines) ReadFrom(r io.Reader) (int64, error) {
+	return wl.readFrom(r, wl.write)
+}
+
+/*
+Close aborts a blocked Write and closes the channel once the final
+unterminated line, if any, is sent without waiting for its receipt.
+Calling it more than once is harmless.  Returns the context's error, and
+discards the final line, when the context is already canceled.
+*/
+func (wl *Wlines) Close() (err error) {
+	wl.closeOnce.Do(func() {
+		close(wl.done)
+		wl.mu.Lock()
+		defer wl.mu.Unlock()
+		line := string(wl.pend)
+		wl.pend = nil
+		if len(line) == 0 {
+			close(wl.lines)
+			return
+		}
+		if err = wl.canceled(); err != nil {
+			close(wl.lines)
+			return
+		}
+		go func() {
+			defer close(wl.lines)
+			wl.send(line, nil)
+		}()
+	})
+	return err
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+func (wl *Wlines) write(p []byte) (int, error) {
+	wl.mu.Lock()
+	defer wl.mu.Unlock()
+	select {
+	case <-wl.done:
+		return 0, os.ErrClosed
+	default:
+	}
+	prior := len(wl.pend)
+	wl.pend = append(wl.pend, p...)
+	var sent int
+	for {
+		i := bytes.Index(wl.pend[sent:], wl.delim)
+		if i < 0 {
+			break
+		}
+		if err := wl.send(string(wl.pend[sent:sent+i]), wl.done); err != nil {
+			if sent < prior {
+				wl.pend = append(wl.pend[:0], wl.pend[sent:prior]...)
+				return 0, err
+			}
+			wl.pend = wl.pend[:0]
+			return sent - prior, err
+		}
+		sent += i + len(wl.delim)
+	}
+	wl.pend = append(wl.pend[:0], wl.pend[sent:]...)
+	return len(p), nil
+}
+
+// sends a line unless aborted by closing Wlines or canceling the context.
+func (wl *Wlines) send(line string, done <-chan struct{}) error {
+	select {
+	case wl.lines <- line:
+		return nil
+	case <-done:
+		return os.ErrClosed
+	case <-wl.ctx.Done():
+		return wl.canceled()
+	}
+}
+
+// returns the error reporting a canceled context or nil when it's active.
+func (wl *Wlines) canceled() error {
+	if wl.ctx.Err() == nil {
+		return nil
+	}
+	if wl.cancelErr != nil {
+		return wl.cancelErr
+	}
+	return wl.ctx.Err()
+}
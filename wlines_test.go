@@ -0,0 +1,102 @@
+package mckio
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WlinesChunked(t *testing.T) {
+	assrt := assert.New(t)
+	lines := make(chan string)
+	wl := NewWlines(lines, nil)
+	go func() {
+		defer wl.Close()
+		for _, chunk := range []string{"li", "ne 1\nline", " 2\n\nline 3\nli", "ne 4"} {
+			fmt.Fprint(wl, chunk)
+		}
+	}()
+	var rslt []string
+	for line := range lines {
+		rslt = append(rslt, line)
+	}
+	assrt.Equal([]string{"line 1", "line 2", "", "line 3", "line 4"}, rslt)
+	_, err := fmt.Fprint(wl, "line 5\n")
+	assrt.Equal(os.ErrClosed, err)
+}
+func Test_WlinesDelim(t *testing.T) {
+	assrt := assert.New(t)
+	lines := make(chan string, 4)
+	wl := NewWlines(lines, wsDelim("\r\n"))
+	fmt.Fprint(wl, "line 1\r")
+	fmt.Fprint(wl, "\nline 2\r\n")
+	assrt.Nil(wl.Close())
+	assrt.Equal("line 1", <-lines)
+	assrt.Equal("line 2", <-lines)
+	_, open := <-lines
+	assrt.False(open)
+}
+func Test_WlinesCancel(t *testing.T) {
+	assrt := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	lines := make(chan string, 1)
+	wl := NewWlines(lines, chanCtx{ctx: ctx})
+	fmt.Fprint(wl, "line 1\nli")
+	cancel()
+	// "line 2" can't be sent as the channel is full
+	sz, err := wl.Write([]byte("ne 2\nline 3\n"))
+	assrt.Zero(sz)
+	assrt.Equal(context.Canceled, err)
+	// the partial line preceding the aborted write remains pending
+	assrt.Equal(context.Canceled, wl.Close())
+	assrt.Equal("line 1", <-lines)
+}
+func Test_WlinesCancelPartial(t *testing.T) {
+	assrt := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	lines := make(chan string, 1)
+	wl := NewWlines(lines, chanCtx{ctx: ctx})
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	// "line 1" fills the channel and "line 2" blocks until canceled
+	sz, err := wl.Write([]byte("line 1\nline 2\n"))
+	assrt.Equal(7, sz)
+	assrt.Equal(context.Canceled, err)
+	assrt.Equal("line 1", <-lines)
+}
+func Test_WlinesCloseUnreceived(t *testing.T) {
+	assrt := assert.New(t)
+	lines := make(chan string)
+	wl := NewWlines(lines, nil)
+	fmt.Fprint(wl, "line 1")
+	closed := make(chan error)
+	go func() { closed <- wl.Close() }()
+	select {
+	case err := <-closed:
+		assrt.Nil(err)
+	case <-time.After(time.Second):
+		assrt.Fail("Close waited for a receiver")
+	}
+	assrt.Equal("line 1", <-lines)
+	_, open := <-lines
+	assrt.False(open)
+}
+func Test_WlinesCloseUnreceivedCancel(t *testing.T) {
+	assrt := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	lines := make(chan string)
+	wl := NewWlines(lines, chanCtx{ctx: ctx})
+	fmt.Fprint(wl, "line 1")
+	assrt.Nil(wl.Close())
+	cancel()
+	// canceling the context discards the final line and closes the channel
+	time.Sleep(10 * time.Millisecond)
+	_, open := <-lines
+	assrt.False(open)
+}
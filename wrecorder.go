@@ -0,0 +1,121 @@
+package mckio
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+Wrecorder implements a concurrency safe io.Writer recording everything
+written to it.  Use it as the destination of logs or output produced by
+several goroutines, then assert on the recording without data races.
+
+- Contains and MatchRegexp inspect the output recorded so far.
+
+- WaitFor blocks until the recording contains a substring, so a test can
+synchronize with asynchronous output instead of sleeping.
+*/
+type Wrecorder struct {
+	writerEntries
+	mu      sync.Mutex
+	buf     strings.Builder
+	changed chan struct{}
+}
+
+/*
+NewWrecorder creates an empty Wrecorder.
+*/
+func NewWrecorder() *Wrecorder {
+	return &Wrecorder{changed: make(chan struct{})}
+}
+
+/*
+Write appends p to the recording.  It never fails.
+*/
+func (wr *Wrecorder) Write(p []byte) (int, error) {
+	wr.entry.record(OpWrite)
+	return wr.write(p)
+}
+
+/*
+WriteString implements io.StringWriter, writing s like Write.
+*/
+func (wr *Wrecorder) WriteString(s string) (int, error) {
+	return wr.writeString(s, wr.write)
+}
+
+/*
+ReadFrom implements io.ReaderFrom, writing the content of 'r' until io.EOF
+as a series of writes like Write.
+*/
+func (wr *Wrecorder) ReadFrom(r io.Reader) (int64, error) {
+	return wr.readFrom(r, wr.write)
+}
+
+/*
+String returns the output recorded so far.
+*/
+func (wr *Wrecorder) String() string {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	return wr.buf.String()
+}
+
+/*
+Contains reports whether the recording contains substr.
+*/
+func (wr *Wrecorder) Contains(substr string) bool {
+	return strings.Contains(wr.String(), substr)
+}
+
+/*
+MatchRegexp reports whether the recording matches the regular expression
+expr.  It panics if expr fails to compile, as a malformed expression is a
+defect of the test itself.
+*/
+func (wr *Wrecorder) MatchRegexp(expr string) bool {
+	return regexp.MustCompile(expr).MatchString(wr.String())
+}
+
+/*
+WaitFor blocks until the recording contains substr or the timeout elapses.
+The error returned on timeout wraps os.ErrDeadlineExceeded and includes an
+excerpt of the recording.
+*/
+func (wr *Wrecorder) WaitFor(substr string, timeout time.Duration) error {
+	expire := time.NewTimer(timeout)
+	defer expire.Stop()
+	for {
+		wr.mu.Lock()
+		content := wr.buf.String()
+		changed := wr.changed
+		wr.mu.Unlock()
+		if strings.Contains(content, substr) {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-expire.C:
+			return fmt.Errorf("mckio: %q not written within %v - output \"%s\": %w", substr, timeout, excerpt(content), os.ErrDeadlineExceeded)
+		}
+	}
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+func (wr *Wrecorder) write(p []byte) (int, error) {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	wr.buf.Write(p)
+	// wake every waiter so it reevaluates the recording.
+	close(wr.changed)
+	wr.changed = make(chan struct{})
+	return len(p), nil
+}
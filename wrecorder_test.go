@@ -0,0 +1,54 @@
+package mckio
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WrecorderConcurrentWrites(t *testing.T) {
+	assrt := assert.New(t)
+	wr := NewWrecorder()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				fmt.Fprintf(wr, "worker %d entry %d\n", i, j)
+			}
+		}(i)
+	}
+	wg.Wait()
+	assrt.True(wr.Contains("worker 3 entry 99\n"))
+	assrt.False(wr.Contains("worker 4"))
+	assrt.True(wr.MatchRegexp(`(?m)^worker 0 entry \d+$`))
+	assrt.False(wr.MatchRegexp(`entry 100`))
+	assrt.Panics(func() { wr.MatchRegexp(`(`) })
+}
+func Test_WrecorderWaitFor(t *testing.T) {
+	assrt := assert.New(t)
+	wr := NewWrecorder()
+	go func() {
+		fmt.Fprint(wr, "server ")
+		time.Sleep(5 * time.Millisecond)
+		fmt.Fprint(wr, "ready\n")
+	}()
+	assrt.Nil(wr.WaitFor("server ready", time.Second))
+	// satisfied by the existing recording
+	assrt.Nil(wr.WaitFor("ready", 0))
+}
+func Test_WrecorderWaitForTimeout(t *testing.T) {
+	assrt := assert.New(t)
+	wr := NewWrecorder()
+	fmt.Fprint(wr, "server starting\n")
+	err := wr.WaitFor("server ready", 10*time.Millisecond)
+	assrt.True(errors.Is(err, os.ErrDeadlineExceeded))
+	assrt.Contains(err.Error(), `"server ready"`)
+	assrt.Contains(err.Error(), "server starting")
+}
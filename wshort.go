@@ -0,0 +1,104 @@
+package mckio
+
+import (
+	"io"
+	"sync"
+)
+
+/*
+Wshort implements an io.Writer that intentionally accepts fewer bytes than
+provided, forwarding only the accepted bytes to a destination writer.  It
+verifies callers loop until all bytes are written instead of assuming a
+single Write accepts everything.
+
+The following behavior of Wshort can be configured:
+
+- BehaviorChunkSizer (optional) - limits the number of bytes accepted by
+each Write.  When undefined - Write accepts half of 'p', rounded up.
+
+- BehaviorShortWriteErrer (optional) - specifies the error returned along
+with a short count, like io.ErrShortWrite.  When undefined - a short count
+is returned with a nil error, which io.Writer forbids, but careless
+writers nonetheless produce.
+
+- Wshort is concurrency safe.
+*/
+type Wshort struct {
+	writerEntries
+	mu       sync.Mutex
+	dest     io.Writer
+	chunk    int
+	shortErr error
+}
+
+/*
+BehaviorShortWriteErrer supplies the error returned by a Write accepting
+fewer bytes than provided.
+*/
+type BehaviorShortWriteErrer interface {
+	BehaviorShortWriteErr() error
+}
+
+/*
+NewWshort creates a short writer forwarding accepted bytes to dest whose
+behavior can be configured using BehaviorChunkSizer and
+BehaviorShortWriteErrer.  A nil dest discards the accepted bytes.
+*/
+func NewWshort(dest io.Writer, behavior interface{}) *Wshort {
+	if dest == nil {
+		dest = io.Discard
+	}
+	ws := &Wshort{dest: dest}
+	if bcs, ok := behavior.(BehaviorChunkSizer); ok {
+		ws.chunk = bcs.BehaviorChunkSize()
+	}
+	if bse, ok := behavior.(BehaviorShortWriteErrer); ok {
+		ws.shortErr = bse.BehaviorShortWriteErr()
+	}
+	return ws
+}
+
+/*
+Write forwards a prefix of p to the destination writer.  When p is
+truncated, it reports the short count along with the configured error.
+*/
+func (ws *Wshort) Write(p []byte) (int, error) {
+	ws.entry.record(OpWrite)
+	return ws.write(p)
+}
+
+/*
+WriteString implements io.StringWriter, writing s like Write.
+*/
+func (ws *Wshort) WriteString(s string) (int, error) {
+	return ws.writeString(s, ws.write)
+}
+
+/*
+ReadFrom implements io.ReaderFrom, writing the content of 'r' until io.EOF
+as a series of writes like Write.
+*/
+func (ws *Wshort) ReadFrom(r io.Reader) (int64, error) {
+	return ws.readFrom(r, ws.write)
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+func (ws *Wshort) write(p []byte) (int, error) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	accept := (len(p) + 1) / 2
+	if ws.chunk > 0 {
+		accept = ws.chunk
+	}
+	if accept >= len(p) {
+		return ws.dest.Write(p)
+	}
+	n, err := ws.dest.Write(p[:accept])
+	if err != nil {
+		return n, err
+	}
+	return n, ws.shortErr
+}
@@ -0,0 +1,58 @@
+package mckio
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WshortHalf(t *testing.T) {
+	assrt := assert.New(t)
+	var buf bytes.Buffer
+	ws := NewWshort(&buf, nil)
+	sz, err := ws.Write([]byte("01234"))
+	assrt.Equal(3, sz)
+	assrt.Nil(err)
+	sz, err = ws.Write([]byte("a"))
+	assrt.Equal(1, sz)
+	assrt.Nil(err)
+	assrt.Equal("012a", buf.String())
+}
+func Test_WshortChunkErr(t *testing.T) {
+	assrt := assert.New(t)
+	var buf bytes.Buffer
+	ws := NewWshort(&buf, shortErr{chunkSize: 4, err: io.ErrShortWrite})
+	p := []byte("0123456789")
+	var writes int
+	for len(p) > 0 {
+		sz, err := ws.Write(p)
+		p = p[sz:]
+		writes++
+		if len(p) > 0 {
+			assrt.Equal(io.ErrShortWrite, err)
+			continue
+		}
+		assrt.Nil(err)
+	}
+	assrt.Equal(3, writes)
+	assrt.Equal("0123456789", buf.String())
+}
+func Test_WshortCopy(t *testing.T) {
+	assrt := assert.New(t)
+	var buf bytes.Buffer
+	ws := NewWshort(&buf, shortErr{chunkSize: 3, err: io.ErrShortWrite})
+	// io.Copy fails instead of looping on a short write
+	_, err := io.Copy(ws, bytes.NewReader([]byte("0123456789")))
+	assrt.Equal(io.ErrShortWrite, err)
+}
+
+type shortErr struct {
+	chunkSize
+	err error
+}
+
+func (se shortErr) BehaviorShortWriteErr() error {
+	return se.err
+}
@@ -0,0 +1,144 @@
+package mckio
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+/*
+Wslow implements an io.Writer that throttles the bytes it accepts per second
+before forwarding them to a destination writer.  It simulates a slow
+terminal or network sink, so timeout and buffering logic in the writer path
+of the code under test can be validated.
+
+- Write forwards p in slices of at most a tenth of a second's worth of
+bytes, waiting before each slice until the rate permits it.  Time not spent
+writing doesn't accumulate credit, so a burst after idling is throttled too.
+
+The following behavior of Wslow can be configured:
+
+- BehaviorContexter (optional) - supplies a context whose cancellation
+aborts a throttled Write, which returns the number of bytes forwarded before
+cancellation.  When undefined - Write waits until p is forwarded.
+
+- BehaviorCancelErrer (optional) - specifies the error returned by a Write
+aborted due to context cancellation.  When undefined - returns the
+context's error.
+
+- BehaviorClocker (optional) - supplies the Clock pacing the forwarded
+slices.  When undefined - uses the system clock.
+
+- Wslow is concurrency safe.  Concurrent writes share the rate.
+*/
+type Wslow struct {
+	writerEntries
+	mu        sync.Mutex
+	dest      io.Writer
+	rate      int
+	slice     int
+	next      time.Time
+	ctx       context.Context
+	cancelErr error
+	clock     Clock
+}
+
+/*
+NewWslow creates a writer forwarding to dest at most 'rate' bytes per
+second whose behavior can be configured using BehaviorContexter,
+BehaviorCancelErrer, and BehaviorClocker.  A nil dest discards the
+forwarded bytes.  Panics if rate isn't positive.
+*/
+func NewWslow(dest io.Writer, rate int, behavior interface{}) *Wslow {
+	if rate < 1 {
+		panic("mckio: Wslow rate must be positive")
+	}
+	if dest == nil {
+		dest = io.Discard
+	}
+	ws := &Wslow{
+		dest:  dest,
+		rate:  rate,
+		slice: (rate + 9) / 10,
+		ctx:   context.Background(),
+		clock: clockOf(behavior),
+	}
+	if bc, ok := behavior.(BehaviorContexter); ok {
+		ws.ctx = bc.BehaviorContext()
+	}
+	if bce, ok := behavior.(BehaviorCancelErrer); ok {
+		ws.cancelErr = bce.BehaviorCancelErr()
+	}
+	return ws
+}
+
+/*
+Write forwards p to the destination writer no faster than the configured
+rate.
+*/
+func (ws *Wslow) Write(p []byte) (int, error) {
+	ws.entry.record(OpWrite)
+	return ws.write(p)
+}
+
+/*
+WriteString implements io.StringWriter, writing s like Write.
+*/
+func (ws *Wslow) WriteString(s string) (int, error) {
+	return ws.writeString(s, ws.write)
+}
+
+/*
+ReadFrom implements io.ReaderFrom, writing the content of 'r' until io.EOF
+as a series of writes like Write.
+*/
+func (ws *Wslow) ReadFrom(r io.Reader) (int64, error) {
+	return ws.readFrom(r, ws.write)
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+func (ws *Wslow) write(p []byte) (int, error) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if now := ws.clock.Now(); ws.next.Before(now) {
+		ws.next = now
+	}
+	var n int
+	for n < len(p) {
+		sz := len(p) - n
+		if sz > ws.slice {
+			sz = ws.slice
+		}
+		ws.next = ws.next.Add(time.Duration(int64(sz) * int64(time.Second) / int64(ws.rate)))
+		if err := ws.wait(); err != nil {
+			return n, err
+		}
+		nw, err := ws.dest.Write(p[n : n+sz])
+		n += nw
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// waits until the rate permits forwarding the next slice.
+func (ws *Wslow) wait() error {
+	tmr := ws.clock.NewTimer(ws.next.Sub(ws.clock.Now()))
+	defer tmr.Stop()
+	select {
+	case <-tmr.C():
+		return nil
+	case <-ws.ctx.Done():
+		// the slice wasn't forwarded so its time isn't owed.
+		ws.next = ws.clock.Now()
+		if ws.cancelErr != nil {
+			return ws.cancelErr
+		}
+		return ws.ctx.Err()
+	}
+}
@@ -0,0 +1,38 @@
+package mckio
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WslowRate(t *testing.T) {
+	assrt := assert.New(t)
+	var buf bytes.Buffer
+	ws := NewWslow(&buf, 10000, nil)
+	start := time.Now()
+	sz, err := ws.Write(make([]byte, 500))
+	assrt.Equal(500, sz)
+	assrt.Nil(err)
+	assrt.True(time.Since(start) >= 50*time.Millisecond)
+	assrt.Equal(500, buf.Len())
+}
+func Test_WslowCancel(t *testing.T) {
+	assrt := assert.New(t)
+	var buf bytes.Buffer
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	ws := NewWslow(&buf, 100, chanCtx{ctx: ctx})
+	// the first slice of 10 bytes needs 100ms
+	sz, err := ws.Write(make([]byte, 100))
+	assrt.Zero(sz)
+	assrt.Equal(context.DeadlineExceeded, err)
+	assrt.Zero(buf.Len())
+}
+func Test_WslowRatePanics(t *testing.T) {
+	assrt := assert.New(t)
+	assrt.Panics(func() { NewWslow(nil, 0, nil) })
+}
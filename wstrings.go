@@ -0,0 +1,110 @@
+package mckio
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+)
+
+/*
+Wstrings implements an io.Writer accumulating writes so a test can assert on
+program output as a list of strings, mirroring Rstrings on the read side.
+
+The following behavior of Wstrings can be configured:
+
+- BehaviorDelimer (optional) - specifies the byte sequence separating the
+accumulated output into lines.  When undefined - lines are separated by
+a newline.
+
+Wstrings is concurrency safe, so a test may inspect the output while the
+code under test continues to write.
+*/
+type Wstrings struct {
+	writerEntries
+	mu    sync.Mutex
+	buf   []byte
+	delim []byte
+}
+
+/*
+NewWstrings creates an empty Wstrings whose behavior can be configured
+using BehaviorDelimer.
+*/
+func NewWstrings(behavior interface{}) *Wstrings {
+	ws := &Wstrings{delim: []byte{'\n'}}
+	if bd, ok := behavior.(BehaviorDelimer); ok && len(bd.BehaviorDelim()) > 0 {
+		ws.delim = bd.BehaviorDelim()
+	}
+	return ws
+}
+
+/*
+Write appends p to the accumulated output.  It never fails.
+*/
+func (ws *Wstrings) Write(p []byte) (int, error) {
+	ws.entry.record(OpWrite)
+	return ws.write(p)
+}
+
+/*
+WriteString implements io.StringWriter, writing s like Write.
+*/
+func (ws *Wstrings) WriteString(s string) (int, error) {
+	return ws.writeString(s, ws.write)
+}
+
+/*
+ReadFrom implements io.ReaderFrom, writing the content of 'r' until io.EOF
+as a series of writes like Write.
+*/
+func (ws *Wstrings) ReadFrom(r io.Reader) (int64, error) {
+	return ws.readFrom(r, ws.write)
+}
+
+/*
+Lines splits the accumulated output by the delimiter.  Delimiters are
+removed and the text following the final delimiter, when not empty, is
+returned as the last line.  Lines returns nil when nothing was written.
+*/
+func (ws *Wstrings) Lines() []string {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if len(ws.buf) == 0 {
+		return nil
+	}
+	lines := strings.Split(string(ws.buf), string(ws.delim))
+	if bytes.HasSuffix(ws.buf, ws.delim) {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+/*
+All returns the accumulated output as a single string.
+*/
+func (ws *Wstrings) All() string {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	return string(ws.buf)
+}
+
+/*
+Reset discards the accumulated output.
+*/
+func (ws *Wstrings) Reset() {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.buf = nil
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+func (ws *Wstrings) write(p []byte) (int, error) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.buf = append(ws.buf, p...)
+	return len(p), nil
+}
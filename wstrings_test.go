@@ -0,0 +1,43 @@
+package mckio
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WstringsLines(t *testing.T) {
+	assrt := assert.New(t)
+	ws := NewWstrings(nil)
+	assrt.Nil(ws.Lines())
+	fmt.Fprint(ws, "line 1\nli")
+	assrt.Equal([]string{"line 1", "li"}, ws.Lines())
+	fmt.Fprint(ws, "ne 2\n")
+	assrt.Equal([]string{"line 1", "line 2"}, ws.Lines())
+	fmt.Fprint(ws, "\n")
+	assrt.Equal([]string{"line 1", "line 2", ""}, ws.Lines())
+	assrt.Equal("line 1\nline 2\n\n", ws.All())
+}
+func Test_WstringsDelim(t *testing.T) {
+	assrt := assert.New(t)
+	ws := NewWstrings(wsDelim("\r\n"))
+	fmt.Fprint(ws, "line 1\r\nline 2\r\n")
+	assrt.Equal([]string{"line 1", "line 2"}, ws.Lines())
+}
+func Test_WstringsReset(t *testing.T) {
+	assrt := assert.New(t)
+	ws := NewWstrings(nil)
+	fmt.Fprintln(ws, "line 1")
+	ws.Reset()
+	assrt.Empty(ws.All())
+	assrt.Nil(ws.Lines())
+	fmt.Fprintln(ws, "line 2")
+	assrt.Equal([]string{"line 2"}, ws.Lines())
+}
+
+type wsDelim string
+
+func (d wsDelim) BehaviorDelim() []byte {
+	return []byte(d)
+}
@@ -0,0 +1,69 @@
+package mckio
+
+import (
+	"io"
+	"sync"
+)
+
+/*
+Wtee implements an io.Writer recording every byte written to it while also
+forwarding it to a real destination, like os.Stdout or a file.  Developers
+can therefore watch output live while debugging and still assert on it
+afterward.
+
+- Wtee embeds a Wrecorder, offering its assertion helpers, like Contains
+and WaitFor, over the recorded output.
+
+- Bytes are recorded even when the destination fails to accept them, as
+the recording reflects what the code under test wrote.
+
+- Wtee is concurrency safe.  Writes reach the destination in the order
+they're recorded.
+*/
+type Wtee struct {
+	*Wrecorder
+	mu   sync.Mutex
+	dest io.Writer
+}
+
+/*
+NewWtee creates a writer recording output and forwarding it to dest.
+*/
+func NewWtee(dest io.Writer) *Wtee {
+	return &Wtee{Wrecorder: NewWrecorder(), dest: dest}
+}
+
+/*
+Write records p then forwards it to the destination writer, returning the
+destination's result.
+*/
+func (wt *Wtee) Write(p []byte) (int, error) {
+	wt.entry.record(OpWrite)
+	return wt.write(p)
+}
+
+/*
+WriteString implements io.StringWriter, writing s like Write.
+*/
+func (wt *Wtee) WriteString(s string) (int, error) {
+	return wt.writeString(s, wt.write)
+}
+
+/*
+ReadFrom implements io.ReaderFrom, writing the content of 'r' until io.EOF
+as a series of writes like Write.
+*/
+func (wt *Wtee) ReadFrom(r io.Reader) (int64, error) {
+	return wt.readFrom(r, wt.write)
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+func (wt *Wtee) write(p []byte) (int, error) {
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+	wt.Wrecorder.write(p)
+	return wt.dest.Write(p)
+}
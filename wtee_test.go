@@ -0,0 +1,31 @@
+package mckio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WteeForward(t *testing.T) {
+	assrt := assert.New(t)
+	var dest bytes.Buffer
+	wt := NewWtee(&dest)
+	fmt.Fprintln(wt, "line 1")
+	fmt.Fprintln(wt, "line 2")
+	assrt.Equal("line 1\nline 2\n", dest.String())
+	assrt.Equal(dest.String(), wt.String())
+	assrt.True(wt.Contains("line 2"))
+	assrt.Nil(wt.WaitFor("line 1", time.Second))
+}
+func Test_WteeDestErr(t *testing.T) {
+	assrt := assert.New(t)
+	wt := NewWtee(NewWerr(nil, BrokenPipe{}))
+	_, err := fmt.Fprint(wt, "line 1")
+	assrt.True(errors.Is(err, syscall.EPIPE))
+	assrt.Equal("line 1", wt.String())
+}
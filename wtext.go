@@ -0,0 +1,122 @@
+package mckio
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"unicode/utf8"
+)
+
+/*
+Wtext implements an io.Writer accumulating streamed text that exposes an
+always valid UTF-8 preview of the content written so far.  Writers often
+split a multi-byte rune across several writes, so asserting on partial
+output could otherwise encounter an invalid rune boundary.
+
+- Preview excludes an incomplete rune at the end of the content until
+the write completing it arrives.
+
+- Wtext is concurrency safe, so a test may inspect the preview while the
+code under test continues to write.
+*/
+type Wtext struct {
+	writerEntries
+	mu  sync.Mutex
+	buf []byte
+}
+
+/*
+NewWtext creates an empty Wtext.
+*/
+func NewWtext() *Wtext {
+	return &Wtext{}
+}
+
+/*
+Write appends p to the accumulated text.  It never fails.
+*/
+func (wt *Wtext) Write(p []byte) (int, error) {
+	wt.entry.record(OpWrite)
+	return wt.write(p)
+}
+
+/*
+WriteString implements io.StringWriter, writing s like Write.
+*/
+func (wt *Wtext) WriteString(s string) (int, error) {
+	return wt.writeString(s, wt.write)
+}
+
+/*
+ReadFrom implements io.ReaderFrom, writing the content of 'r' until io.EOF
+as a series of writes like Write.
+*/
+func (wt *Wtext) ReadFrom(r io.Reader) (int64, error) {
+	return wt.readFrom(r, wt.write)
+}
+
+/*
+Preview returns at most 'max' bytes of the text written so far as valid
+UTF-8.  An incomplete rune at the end of the text is omitted, invalid byte
+sequences elsewhere are replaced by utf8.RuneError, then the result is
+truncated at a rune boundary.  A negative 'max' returns the entire valid text.
+*/
+func (wt *Wtext) Preview(max int) string {
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+	text := wt.buf[:len(wt.buf)-partialRuneLen(wt.buf)]
+	// a replacement rune may be wider than the bytes it replaces, so
+	// truncate the valid text
+	text = bytes.ToValidUTF8(text, []byte(string(utf8.RuneError)))
+	if max > -1 && len(text) > max {
+		text = text[:max]
+		text = text[:len(text)-partialRuneLen(text)]
+	}
+	return string(text)
+}
+
+/*
+Pending returns the number of bytes of an incomplete rune at the end of the
+text, awaiting the write that completes it.
+*/
+func (wt *Wtext) Pending() int {
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+	return partialRuneLen(wt.buf)
+}
+
+/*
+String returns the raw text written so far, which may end with an
+incomplete rune.
+*/
+func (wt *Wtext) String() string {
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+	return string(wt.buf)
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+func (wt *Wtext) write(p []byte) (int, error) {
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+	wt.buf = append(wt.buf, p...)
+	return len(p), nil
+}
+
+// length of the incomplete rune that ends text.  examines at most the
+// final utf8.UTFMax-1 bytes, as a longer sequence can't be incomplete.
+func partialRuneLen(text []byte) int {
+	for i := 1; i < utf8.UTFMax && i <= len(text); i++ {
+		b := text[len(text)-i]
+		if utf8.RuneStart(b) {
+			if utf8.FullRune(text[len(text)-i:]) {
+				return 0
+			}
+			return i
+		}
+	}
+	return 0
+}
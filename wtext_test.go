@@ -0,0 +1,44 @@
+package mckio
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WtextSplitRune(t *testing.T) {
+	assrt := assert.New(t)
+	wt := NewWtext()
+	euro := []byte("€") // 3 byte rune
+	fmt.Fprint(wt, "cost: ")
+	wt.Write(euro[:1])
+	assrt.Equal("cost: ", wt.Preview(-1))
+	assrt.Equal(1, wt.Pending())
+	wt.Write(euro[1:2])
+	assrt.Equal("cost: ", wt.Preview(-1))
+	wt.Write(euro[2:])
+	assrt.Equal("cost: €", wt.Preview(-1))
+	assrt.Zero(wt.Pending())
+	assrt.Equal("cost: €", wt.String())
+}
+func Test_WtextTruncateAtRuneBoundary(t *testing.T) {
+	assrt := assert.New(t)
+	wt := NewWtext()
+	fmt.Fprint(wt, "a€b")
+	// truncating within the euro omits it entirely
+	assrt.Equal("a", wt.Preview(2))
+	assrt.Equal("a€", wt.Preview(4))
+	assrt.Equal("", wt.Preview(0))
+}
+func Test_WtextInvalidReplaced(t *testing.T) {
+	assrt := assert.New(t)
+	wt := NewWtext()
+	wt.Write([]byte{'a', 0xff, 'b'})
+	assrt.Equal("a�b", wt.Preview(-1))
+	// the replacement rune counts toward 'max'
+	wt = NewWtext()
+	wt.Write([]byte{'a', 0xff})
+	assrt.Equal("a", wt.Preview(2))
+	assrt.Equal("a�", wt.Preview(4))
+}
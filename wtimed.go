@@ -0,0 +1,99 @@
+package mckio
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+/*
+Wtimed implements an io.Writer recording each Write as a timestamped
+payload, exposing the sequence for assertions about ordering and pacing,
+like a progress bar updating at most every 100ms.
+
+The following behavior of Wtimed can be configured:
+
+- BehaviorClocker (optional) - supplies the Clock stamping each write, so a
+test can control the time.  When undefined - uses the system clock.
+
+Wtimed is concurrency safe.
+*/
+type Wtimed struct {
+	writerEntries
+	mu     sync.Mutex
+	clock  Clock
+	writes []TimedWrite
+}
+
+/*
+TimedWrite is a single Write recorded by Wtimed.
+*/
+type TimedWrite struct {
+	At      time.Time
+	Payload []byte
+}
+
+/*
+NewWtimed creates an empty Wtimed whose clock can be configured using
+BehaviorClocker.
+*/
+func NewWtimed(behavior interface{}) *Wtimed {
+	return &Wtimed{clock: clockOf(behavior)}
+}
+
+/*
+Write records a copy of p stamped with the current time.  It never fails.
+*/
+func (wt *Wtimed) Write(p []byte) (int, error) {
+	wt.entry.record(OpWrite)
+	return wt.write(p)
+}
+
+/*
+WriteString implements io.StringWriter, writing s like Write.
+*/
+func (wt *Wtimed) WriteString(s string) (int, error) {
+	return wt.writeString(s, wt.write)
+}
+
+/*
+ReadFrom implements io.ReaderFrom, writing the content of 'r' until io.EOF
+as a series of writes like Write.
+*/
+func (wt *Wtimed) ReadFrom(r io.Reader) (int64, error) {
+	return wt.readFrom(r, wt.write)
+}
+
+/*
+Writes returns the recorded writes in the order they occurred.
+*/
+func (wt *Wtimed) Writes() []TimedWrite {
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+	return append([]TimedWrite(nil), wt.writes...)
+}
+
+/*
+Intervals returns the time elapsed between each pair of consecutive
+writes.  It's empty when fewer than two writes were recorded.
+*/
+func (wt *Wtimed) Intervals() []time.Duration {
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+	var intervals []time.Duration
+	for i := 1; i < len(wt.writes); i++ {
+		intervals = append(intervals, wt.writes[i].At.Sub(wt.writes[i-1].At))
+	}
+	return intervals
+}
+
+//-----------------------------------------------------------------------------
+//--                         Private Section                                ---
+//-----------------------------------------------------------------------------
+
+func (wt *Wtimed) write(p []byte) (int, error) {
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+	wt.writes = append(wt.writes, TimedWrite{At: wt.clock.Now(), Payload: append([]byte(nil), p...)})
+	return len(p), nil
+}
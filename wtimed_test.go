@@ -0,0 +1,50 @@
+package mckio
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WtimedClock(t *testing.T) {
+	assrt := assert.New(t)
+	clk := &stepClock{at: time.Unix(0, 0), step: 100 * time.Millisecond}
+	wt := NewWtimed(clk)
+	p := []byte("10%")
+	wt.Write(p)
+	copy(p, "50%")
+	wt.Write(p)
+	fmt.Fprint(wt, "100%")
+	writes := wt.Writes()
+	assrt.Len(writes, 3)
+	assrt.Equal(TimedWrite{At: time.Unix(0, 0), Payload: []byte("10%")}, writes[0])
+	assrt.Equal("50%", string(writes[1].Payload))
+	assrt.Equal(time.Unix(0, 0).Add(200*time.Millisecond), writes[2].At)
+	assrt.Equal([]time.Duration{100 * time.Millisecond, 100 * time.Millisecond}, wt.Intervals())
+}
+func Test_WtimedWallClock(t *testing.T) {
+	assrt := assert.New(t)
+	wt := NewWtimed(nil)
+	assrt.Empty(wt.Intervals())
+	before := time.Now()
+	fmt.Fprint(wt, "10%")
+	assrt.False(wt.Writes()[0].At.Before(before))
+}
+
+// advances by step after every reading.  it never waits.
+type stepClock struct {
+	Clock
+	at   time.Time
+	step time.Duration
+}
+
+func (sc *stepClock) BehaviorClock() Clock {
+	return sc
+}
+func (sc *stepClock) Now() time.Time {
+	at := sc.at
+	sc.at = sc.at.Add(sc.step)
+	return at
+}